@@ -7,6 +7,7 @@ toolchain go1.23.11
 require (
 	github.com/mark3labs/mcp-go v0.33.0
 	github.com/spf13/cobra v1.9.1
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
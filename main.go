@@ -1,8 +1,15 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/zhongxiao37/soql-mcp/pkg"
 	"github.com/zhongxiao37/soql-mcp/pkg/resources"
@@ -13,7 +20,18 @@ import (
 )
 
 var (
-	versionFlag bool
+	versionFlag   bool
+	configFlag    string
+	noBrowser     bool
+	loginOrg      string
+	transportFlag string
+	listenFlag    string
+	authTokenFlag string
+	authFileFlag  string
+	orgFlag       string
+	logLevelFlag  string
+	logFormatFlag string
+	logFileFlag   string
 )
 
 func main() {
@@ -26,21 +44,103 @@ func main() {
 				fmt.Printf("soql-mcp version %s (commit: %s, build date: %s)\n", pkg.Version, pkg.Commit, pkg.BuildDate)
 				os.Exit(0)
 			}
-			runServer()
+			runServer(cmd)
 		},
 	}
 
 	rootCmd.Flags().BoolVarP(&versionFlag, "version", "v", false, "Print version information")
+	rootCmd.PersistentFlags().StringVar(&configFlag, "config", "", "Config file (default: $XDG_CONFIG_HOME/soql-mcp/config.yaml)")
+	rootCmd.Flags().StringVar(&orgFlag, "org", "", "Default org profile for tool calls without an org argument")
+	rootCmd.Flags().StringVar(&logLevelFlag, "log-level", "", "Log level: debug, info, warn or error")
+	rootCmd.Flags().StringVar(&logFormatFlag, "log-format", "", "Log format: text or json")
+	rootCmd.Flags().StringVar(&logFileFlag, "log-file", "", "Write logs to this file instead of stderr")
+	rootCmd.Flags().StringVar(&transportFlag, "transport", pkg.TransportStdio, "Transport to serve: stdio, sse or http (streamable HTTP)")
+	rootCmd.Flags().StringVar(&listenFlag, "listen", "127.0.0.1:8080", "Listen address for the sse and http transports")
+	rootCmd.Flags().StringVar(&authTokenFlag, "auth-token", "", "Bearer token required by the sse and http transports")
+	rootCmd.Flags().StringVar(&authFileFlag, "auth-token-file", "", "File of accepted bearer tokens, one per line, for the sse and http transports")
+
+	var loginCmd = &cobra.Command{
+		Use:           "login",
+		Short:         "Log in to Salesforce in a browser",
+		Long:          "Log in to Salesforce with the OAuth web server flow and PKCE, and store the refresh token for the server to use",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runLogin()
+		},
+	}
+	loginCmd.Flags().BoolVar(&noBrowser, "no-browser", false, "Print the login URL instead of opening a browser")
+	loginCmd.Flags().StringVar(&loginOrg, "org", "", "Org profile to log in to (default: the configured default org)")
+	rootCmd.AddCommand(loginCmd)
 
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Printf("Error: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 }
 
-func runServer() {
-	// Load configuration from environment variables
-	config := pkg.LoadConfig()
+func runLogin() error {
+	config, err := pkg.LoadConfig(pkg.LoadOptions{ConfigFile: configFlag})
+	if err != nil {
+		return err
+	}
+	orgConfig, err := config.GetOrg(loginOrg)
+	if err != nil {
+		return err
+	}
+
+	token, err := pkg.Login(context.Background(), orgConfig, pkg.LoginOptions{
+		OpenBrowser: !noBrowser,
+		Out:         os.Stderr,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Logged in to %s as org %s. Refresh token saved to %s\n", token.InstanceURL, orgConfig.Name, orgConfig.SalesforceTokenFile)
+	return nil
+}
+
+func runServer(cmd *cobra.Command) {
+	// Load configuration once from flags, environment variables and the config file
+	flags := map[string]string{}
+	for flag, key := range map[string]string{
+		"transport":       "MCP_TRANSPORT",
+		"listen":          "MCP_LISTEN",
+		"auth-token":      "MCP_AUTH_TOKEN",
+		"auth-token-file": "MCP_AUTH_TOKEN_FILE",
+		"org":             "SALESFORCE_DEFAULT_ORG",
+		"log-level":       "MCP_LOG_LEVEL",
+		"log-format":      "MCP_LOG_FORMAT",
+		"log-file":        "MCP_LOG_FILE",
+	} {
+		if cmd.Flags().Changed(flag) {
+			flags[key] = cmd.Flags().Lookup(flag).Value.String()
+		}
+	}
+	config, err := pkg.LoadConfig(pkg.LoadOptions{ConfigFile: configFlag, Flags: flags})
+	if err != nil {
+		pkg.Logger().Error("failed to load configuration", "error", err)
+		os.Exit(1)
+	}
+	pkg.SetConfig(config)
+
+	// Log to stderr or the log file; stdout carries the stdio transport
+	logger, logCloser, err := pkg.NewLogger(config)
+	if err != nil {
+		pkg.Logger().Error("failed to create logger", "error", err)
+		os.Exit(1)
+	}
+	defer logCloser.Close()
+
+	// Forward logs to MCP clients as notifications at the level each client sets
+	hooks := &server.Hooks{}
+	logger = slog.New(pkg.NewLogForwarder(logger.Handler(), hooks, config.Transport))
+	pkg.SetLogger(logger)
+
+	// Let clients cancel in-flight tool calls
+	cancellations := tools.NewCancellations()
+	cancellations.AddHooks(hooks)
 
 	// Print configuration for debugging
 	if config.Debug {
@@ -53,18 +153,103 @@ func runServer() {
 		config.ServerVersion,
 		server.WithResourceCapabilities(true, true),
 		server.WithToolCapabilities(true),
+		server.WithLogging(),
+		server.WithHooks(hooks),
+		server.WithToolHandlerMiddleware(tools.LoggingMiddleware),
+		server.WithToolHandlerMiddleware(cancellations.Middleware),
+		server.WithToolHandlerMiddleware(tools.ProgressMiddleware),
 	)
+	s.AddNotificationHandler("notifications/cancelled", cancellations.HandleCancelled)
 
 	// Add tools
 	s.AddTool(tools.CreateDebugTool(), tools.DebugHandler)
 	s.AddTool(tools.CreateQueryTool(), tools.QueryHandler)
+	s.AddTool(tools.CreateValidateSOQLTool(), tools.ValidateSOQLHandler)
 	s.AddTool(tools.CreateDescribeTool(), tools.DescribeHandler)
+	s.AddTool(tools.CreateListObjectsTool(), tools.ListObjectsHandler)
+	s.AddTool(tools.CreateSearchTool(), tools.SearchHandler)
+	s.AddTool(tools.CreateExplainTool(), tools.ExplainHandler)
+	s.AddTool(tools.CreateLimitsTool(), tools.LimitsHandler)
+	s.AddTool(tools.CreateListOrgsTool(), tools.ListOrgsHandler)
+	s.AddTool(tools.CreateBulkQueryTool(), tools.BulkQueryHandler)
+
+	// Record write tools are only available when explicitly enabled
+	if config.AllowWrites {
+		s.AddTool(tools.CreateCreateRecordTool(), tools.CreateRecordHandler)
+		s.AddTool(tools.CreateUpdateRecordTool(), tools.UpdateRecordHandler)
+		s.AddTool(tools.CreateUpsertRecordTool(), tools.UpsertRecordHandler)
+		s.AddTool(tools.CreateDeleteRecordTool(), tools.DeleteRecordHandler)
+		s.AddTool(tools.CreateBulkIngestTool(), tools.BulkIngestHandler)
+	}
 
 	// Add terms resource using the new resources package
 	s.AddResource(resources.CreateTermsResource(config.ResourcePath), resources.TermsResourceHandler)
 
 	// Start the stdio server
-	if err := server.ServeStdio(s); err != nil {
-		fmt.Printf("Server error: %v\n", err)
+	if config.Transport == pkg.TransportStdio {
+		logger.Info("serving stdio transport")
+		errorLogger := slog.NewLogLogger(logger.Handler(), slog.LevelError)
+		if err := server.ServeStdio(s, server.WithErrorLogger(errorLogger)); err != nil {
+			logger.Error("server error", "error", err)
+		}
+		return
+	}
+
+	if err := serveHTTP(s, config); err != nil {
+		logger.Error("server error", "error", err)
+		logCloser.Close()
+		os.Exit(1)
 	}
 }
+
+// httpTransport is implemented by the SSE and streamable HTTP servers
+type httpTransport interface {
+	http.Handler
+	Shutdown(ctx context.Context) error
+}
+
+// serveHTTP serves the SSE or streamable HTTP transport behind bearer token auth until
+// SIGINT or SIGTERM, then shuts down gracefully
+func serveHTTP(s *server.MCPServer, config *pkg.Config) error {
+	auth := &pkg.BearerTokenAuth{Token: config.AuthToken, TokenFile: config.AuthTokenFile}
+	if err := auth.Validate(); err != nil {
+		return err
+	}
+
+	httpServer := &http.Server{Addr: config.ListenAddr, ReadHeaderTimeout: 10 * time.Second}
+	var transport httpTransport
+	mux := http.NewServeMux()
+	if config.Transport == pkg.TransportSSE {
+		sseServer := server.NewSSEServer(s, server.WithHTTPServer(httpServer))
+		mux.Handle("/", sseServer)
+		transport = sseServer
+	} else {
+		httpTransportServer := server.NewStreamableHTTPServer(s, server.WithStreamableHTTPServer(httpServer))
+		mux.Handle("/mcp", httpTransportServer)
+		transport = httpTransportServer
+	}
+	httpServer.Handler = auth.Middleware(mux)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, 1)
+	go func() {
+		pkg.Logger().Info("serving network transport", "transport", config.Transport, "listen", config.ListenAddr)
+		errCh <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+	}
+
+	pkg.Logger().Info("shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return transport.Shutdown(shutdownCtx)
+}
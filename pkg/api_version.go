@@ -0,0 +1,94 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultAPIVersion is the REST API version used when SALESFORCE_API_VERSION is not set
+const DefaultAPIVersion = "v57.0"
+
+// APIVersionLatest selects the newest API version the org supports
+const APIVersionLatest = "latest"
+
+// SalesforceAPIVersion represents one entry of the /services/data versions list
+type SalesforceAPIVersion struct {
+	Label   string `json:"label"`
+	URL     string `json:"url"`
+	Version string `json:"version"`
+}
+
+// normalizeAPIVersion accepts "57.0", "v57.0" or "latest" and returns "v57.0" or "latest"
+func normalizeAPIVersion(version string) (string, error) {
+	version = strings.TrimSpace(version)
+	if strings.EqualFold(version, APIVersionLatest) {
+		return APIVersionLatest, nil
+	}
+	number := strings.TrimPrefix(strings.ToLower(version), "v")
+	if _, err := strconv.ParseFloat(number, 64); err != nil || !strings.Contains(number, ".") {
+		return "", fmt.Errorf("invalid SALESFORCE_API_VERSION %q (expected e.g. 60.0, v60.0 or latest)", version)
+	}
+	return "v" + number, nil
+}
+
+// resolveAPIVersion returns the configured API version, asking the org for its newest
+// version when the setting is latest
+func (sf *SalesforceClient) resolveAPIVersion(ctx context.Context) (string, error) {
+	version, err := normalizeAPIVersion(sf.config.SalesforceAPIVersion)
+	if err != nil {
+		return "", err
+	}
+	if version != APIVersionLatest {
+		return version, nil
+	}
+
+	auth, err := sf.session()
+	if err != nil {
+		return "", err
+	}
+
+	var versions []SalesforceAPIVersion
+	if err := sf.getJSON(ctx, auth.InstanceURL+"/services/data", "API version discovery", &versions); err != nil {
+		return "", err
+	}
+
+	latest, latestNumber := "", 0.0
+	for _, v := range versions {
+		number, err := strconv.ParseFloat(v.Version, 64)
+		if err == nil && number > latestNumber {
+			latest, latestNumber = v.Version, number
+		}
+	}
+	if latest == "" {
+		return "", fmt.Errorf("org did not report any API versions")
+	}
+	return "v" + latest, nil
+}
+
+// apiVersion returns the REST API version the client uses
+func (sf *SalesforceClient) apiVersion() string {
+	sf.authMutex.RLock()
+	defer sf.authMutex.RUnlock()
+	return sf.version
+}
+
+// APIVersion returns the REST API version the client resolved at authentication
+func (sf *SalesforceClient) APIVersion() string {
+	return sf.apiVersion()
+}
+
+// dataURL builds a versioned REST API URL, e.g. dataURL("limits") returns
+// <instance>/services/data/v60.0/limits. Every REST endpoint goes through it.
+func (sf *SalesforceClient) dataURL(path string) (string, error) {
+	auth, err := sf.session()
+	if err != nil {
+		return "", err
+	}
+	version := sf.apiVersion()
+	if version == "" {
+		return "", fmt.Errorf("API version not resolved, call Authenticate() first")
+	}
+	return fmt.Sprintf("%s/services/data/%s/%s", auth.InstanceURL, version, strings.TrimPrefix(path, "/")), nil
+}
@@ -0,0 +1,83 @@
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAPIVersionDiscovery(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured string
+		// versions is the /services/data response
+		versions string
+		want     string
+		err      string
+		// discovered is set when the versions list must be requested
+		discovered bool
+	}{
+		{
+			name:       "latest",
+			configured: "latest",
+			versions:   `[{"label":"Summer '24","url":"/services/data/v61.0","version":"61.0"},{"label":"Winter '25","url":"/services/data/v62.0","version":"62.0"},{"label":"Spring '13","url":"/services/data/v9.0","version":"9.0"}]`,
+			want:       "v62.0",
+			discovered: true,
+		},
+		{name: "fixed version", configured: "60.0", want: "v60.0"},
+		{name: "fixed version with prefix", configured: "v58.0", want: "v58.0"},
+		{name: "no versions reported", configured: "LATEST", versions: `[]`, err: "did not report any API versions", discovered: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var server *httptest.Server
+			discovered := false
+			var paths []string
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch {
+				case strings.HasSuffix(r.URL.Path, "/oauth2/token"):
+					json.NewEncoder(w).Encode(SalesforceAuth{AccessToken: "token", InstanceURL: server.URL})
+				case strings.HasSuffix(r.URL.Path, "/oauth2/introspect"):
+					w.Write([]byte(`{"active":false}`))
+				case r.URL.Path == "/services/data":
+					discovered = true
+					w.Write([]byte(test.versions))
+				default:
+					paths = append(paths, r.URL.Path)
+					w.Write([]byte(`{"totalSize":0,"done":true,"records":[]}`))
+				}
+			}))
+			defer server.Close()
+
+			sf, err := newTestClientManager(server.URL, test.configured).GetClient("")
+			if discovered != test.discovered {
+				t.Errorf("versions requested = %t, want %t", discovered, test.discovered)
+			}
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("GetClient: got %v, want an error containing %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetClient failed: %v", err)
+			}
+			if sf.APIVersion() != test.want {
+				t.Errorf("APIVersion() = %q, want %q", sf.APIVersion(), test.want)
+			}
+
+			// REST calls use the resolved version
+			if _, err := sf.Query(context.Background(), "SELECT Id FROM Account", 0); err != nil {
+				t.Fatalf("Query failed: %v", err)
+			}
+			if want := "/services/data/" + test.want + "/query"; len(paths) != 1 || paths[0] != want {
+				t.Errorf("requested %q, want %q", paths, want)
+			}
+		})
+	}
+}
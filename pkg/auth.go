@@ -0,0 +1,273 @@
+package pkg
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supported values for SALESFORCE_AUTH_FLOW
+const (
+	AuthFlowPassword  = "password"
+	AuthFlowJWTBearer = "jwt"
+	AuthFlowWebServer = "web"
+	AuthFlowSfdx      = "sfdx"
+)
+
+// AuthFlow builds the OAuth token request for one Salesforce grant type
+type AuthFlow interface {
+	// Validate checks that the configuration has everything the flow needs
+	Validate(config *OrgConfig) error
+	// TokenRequest returns the token endpoint and the form values to post to it
+	TokenRequest(config *OrgConfig) (string, url.Values, error)
+}
+
+var authFlows = map[string]AuthFlow{
+	AuthFlowPassword:  passwordFlow{},
+	AuthFlowJWTBearer: jwtBearerFlow{},
+	AuthFlowWebServer: webServerFlow{},
+	AuthFlowSfdx:      sfdxFlow{},
+}
+
+// GetAuthFlow returns the auth flow registered under name
+func GetAuthFlow(name string) (AuthFlow, error) {
+	flow, ok := authFlows[strings.ToLower(name)]
+	if !ok {
+		names := make([]string, 0, len(authFlows))
+		for n := range authFlows {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unsupported SALESFORCE_AUTH_FLOW %q (supported: %s)", name, strings.Join(names, ", "))
+	}
+	return flow, nil
+}
+
+// tokenURL returns the OAuth token endpoint for a login or instance URL
+func tokenURL(baseURL string) string {
+	return strings.TrimRight(baseURL, "/") + "/services/oauth2/token"
+}
+
+// parseIssuedAt converts the issued_at token field (milliseconds since the epoch)
+// to a time, falling back to now when it is missing
+func parseIssuedAt(issuedAt string) time.Time {
+	ms, err := strconv.ParseInt(issuedAt, 10, 64)
+	if err != nil || ms <= 0 {
+		return time.Now()
+	}
+	return time.UnixMilli(ms)
+}
+
+// introspectExpiry asks Salesforce when an access token expires. Introspection needs the
+// connected app's client secret, so a zero time is returned when none is configured.
+func introspectExpiry(config *OrgConfig, auth *SalesforceAuth) (time.Time, error) {
+	if config.SalesforceClientID == "" || config.SalesforceClientSecret == "" || auth.InstanceURL == "" {
+		return time.Time{}, nil
+	}
+
+	data := url.Values{}
+	data.Set("token", auth.AccessToken)
+	data.Set("token_type_hint", "access_token")
+	data.Set("client_id", config.SalesforceClientID)
+	data.Set("client_secret", config.SalesforceClientSecret)
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.PostForm(strings.TrimRight(auth.InstanceURL, "/")+"/services/oauth2/introspect", data)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to make introspection request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return time.Time{}, fmt.Errorf("token introspection failed with status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Active bool  `json:"active"`
+		Exp    int64 `json:"exp"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse introspection response: %v", err)
+	}
+	if !result.Active || result.Exp == 0 {
+		return time.Time{}, nil
+	}
+	return time.Unix(result.Exp, 0), nil
+}
+
+// passwordFlow implements the OAuth username-password grant
+type passwordFlow struct{}
+
+func (passwordFlow) Validate(config *OrgConfig) error {
+	if config.SalesforceClientID == "" {
+		return fmt.Errorf("SALESFORCE_CLIENT_ID is required")
+	}
+	if config.SalesforceClientSecret == "" {
+		return fmt.Errorf("SALESFORCE_CLIENT_SECRET is required")
+	}
+	if config.SalesforceUsername == "" {
+		return fmt.Errorf("SALESFORCE_USERNAME is required")
+	}
+	if config.SalesforcePassword == "" {
+		return fmt.Errorf("SALESFORCE_PASSWORD is required")
+	}
+	return nil
+}
+
+func (passwordFlow) TokenRequest(config *OrgConfig) (string, url.Values, error) {
+	data := url.Values{}
+	data.Set("grant_type", "password")
+	data.Set("client_id", config.SalesforceClientID)
+	data.Set("client_secret", config.SalesforceClientSecret)
+	data.Set("username", config.SalesforceUsername)
+	data.Set("password", config.SalesforcePassword+config.SalesforceSecurityToken)
+	return tokenURL(config.SalesforceURL), data, nil
+}
+
+// jwtBearerFlow implements the OAuth JWT bearer grant, signing the assertion locally
+type jwtBearerFlow struct{}
+
+func (jwtBearerFlow) Validate(config *OrgConfig) error {
+	if config.SalesforceClientID == "" {
+		return fmt.Errorf("SALESFORCE_CLIENT_ID is required")
+	}
+	if config.SalesforceUsername == "" {
+		return fmt.Errorf("SALESFORCE_USERNAME is required")
+	}
+	if config.SalesforcePrivateKeyPath == "" {
+		return fmt.Errorf("SALESFORCE_PRIVATE_KEY_PATH is required")
+	}
+	return nil
+}
+
+func (jwtBearerFlow) TokenRequest(config *OrgConfig) (string, url.Values, error) {
+	key, err := loadRSAPrivateKey(config.SalesforcePrivateKeyPath)
+	if err != nil {
+		return "", nil, err
+	}
+
+	audience := config.SalesforceJWTAudience
+	if audience == "" {
+		audience = config.SalesforceURL
+	}
+
+	assertion, err := signJWT(key, map[string]interface{}{
+		"iss": config.SalesforceClientID,
+		"sub": config.SalesforceUsername,
+		"aud": strings.TrimRight(audience, "/"),
+		"exp": time.Now().Add(3 * time.Minute).Unix(),
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	data := url.Values{}
+	data.Set("grant_type", "urn:ietf:params:oauth:grant-type:jwt-bearer")
+	data.Set("assertion", assertion)
+	return tokenURL(config.SalesforceURL), data, nil
+}
+
+// webServerFlow mints access tokens from the refresh token saved by `soql-mcp login`
+type webServerFlow struct{}
+
+func (webServerFlow) Validate(config *OrgConfig) error {
+	if config.SalesforceClientID == "" {
+		return fmt.Errorf("SALESFORCE_CLIENT_ID is required")
+	}
+	token, err := LoadStoredToken(config.SalesforceTokenFile, config.Name)
+	if err != nil {
+		return err
+	}
+	if token == nil || token.RefreshToken == "" {
+		return fmt.Errorf("no stored refresh token found in %s, run `soql-mcp login` first", config.SalesforceTokenFile)
+	}
+	return nil
+}
+
+func (webServerFlow) TokenRequest(config *OrgConfig) (string, url.Values, error) {
+	token, err := LoadStoredToken(config.SalesforceTokenFile, config.Name)
+	if err != nil {
+		return "", nil, err
+	}
+	if token == nil {
+		return "", nil, fmt.Errorf("no stored refresh token found, run `soql-mcp login` first")
+	}
+
+	clientID := token.ClientID
+	if clientID == "" {
+		clientID = config.SalesforceClientID
+	}
+	loginURL := token.LoginURL
+	if loginURL == "" {
+		loginURL = config.SalesforceURL
+	}
+
+	data := url.Values{}
+	data.Set("grant_type", "refresh_token")
+	data.Set("client_id", clientID)
+	if config.SalesforceClientSecret != "" {
+		data.Set("client_secret", config.SalesforceClientSecret)
+	}
+	data.Set("refresh_token", token.RefreshToken)
+	return tokenURL(loginURL), data, nil
+}
+
+// loadRSAPrivateKey reads a PEM encoded PKCS#1 or PKCS#8 RSA private key
+func loadRSAPrivateKey(path string) (*rsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key: %v", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("private key %s is not PEM encoded", path)
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %v", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key %s is not an RSA key", path)
+	}
+	return key, nil
+}
+
+// signJWT builds an RS256 signed JWT from the given claims
+func signJWT(key *rsa.PrivateKey, claims map[string]interface{}) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode JWT header: %v", err)
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode JWT claims: %v", err)
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign JWT: %v", err)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
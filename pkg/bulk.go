@@ -0,0 +1,138 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Bulk API 2.0 job kinds, used as the path segment under /jobs
+const (
+	bulkJobQuery  = "query"
+	bulkJobIngest = "ingest"
+)
+
+// Bulk API 2.0 job states
+const (
+	BulkStateOpen           = "Open"
+	BulkStateUploadComplete = "UploadComplete"
+	BulkStateInProgress     = "InProgress"
+	BulkStateJobComplete    = "JobComplete"
+	BulkStateFailed         = "Failed"
+	BulkStateAborted        = "Aborted"
+)
+
+// Bulk job polling starts at bulkPollInterval and backs off to bulkMaxPollInterval
+var (
+	bulkPollInterval    = 2 * time.Second
+	bulkMaxPollInterval = 15 * time.Second
+)
+
+// SalesforceBulkJob represents a Bulk API 2.0 query or ingest job
+type SalesforceBulkJob struct {
+	ID                     string `json:"id"`
+	Operation              string `json:"operation"`
+	Object                 string `json:"object"`
+	State                  string `json:"state"`
+	ContentType            string `json:"contentType,omitempty"`
+	ExternalIDFieldName    string `json:"externalIdFieldName,omitempty"`
+	ErrorMessage           string `json:"errorMessage,omitempty"`
+	CreatedDate            string `json:"createdDate,omitempty"`
+	SystemModstamp         string `json:"systemModstamp,omitempty"`
+	NumberRecordsProcessed int64  `json:"numberRecordsProcessed"`
+	NumberRecordsFailed    int64  `json:"numberRecordsFailed"`
+	Retries                int    `json:"retries"`
+	TotalProcessingTime    int64  `json:"totalProcessingTime"`
+}
+
+// finished reports whether the job reached a terminal state
+func (job *SalesforceBulkJob) finished() bool {
+	switch job.State {
+	case BulkStateJobComplete, BulkStateFailed, BulkStateAborted:
+		return true
+	}
+	return false
+}
+
+// bulkJobURL builds the URL of a Bulk API 2.0 job resource, e.g. jobs/query/<id>/results
+func (sf *SalesforceClient) bulkJobURL(kind, id, resource string) (string, error) {
+	path := "jobs/" + kind
+	if id != "" {
+		path += "/" + id
+	}
+	if resource != "" {
+		path += "/" + resource
+	}
+	return sf.dataURL(path)
+}
+
+// getBulkJob returns the current state of a bulk job
+func (sf *SalesforceClient) getBulkJob(ctx context.Context, kind, id string) (*SalesforceBulkJob, error) {
+	jobURL, err := sf.bulkJobURL(kind, id, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var job SalesforceBulkJob
+	if err := sf.getJSON(ctx, jobURL, "bulk job status", &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// setBulkJobState moves a bulk job to a new state, e.g. UploadComplete or Aborted
+func (sf *SalesforceClient) setBulkJobState(ctx context.Context, kind, id, state string) (*SalesforceBulkJob, error) {
+	jobURL, err := sf.bulkJobURL(kind, id, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var job SalesforceBulkJob
+	if err := sf.requestJSON(ctx, "PATCH", jobURL, "bulk job update", map[string]string{"state": state}, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// waitForBulkJob polls a bulk job until it finishes, reporting the records processed so
+// far as progress. When ctx is cancelled the job is aborted.
+func (sf *SalesforceClient) waitForBulkJob(ctx context.Context, kind, id string) (*SalesforceBulkJob, error) {
+	interval := bulkPollInterval
+	for {
+		job, err := sf.getBulkJob(ctx, kind, id)
+		if err != nil {
+			if ctx.Err() != nil {
+				sf.abortBulkJob(kind, id)
+			}
+			return nil, err
+		}
+
+		sf.log().DebugContext(ctx, "polled bulk job", "job_id", id, "state", job.State,
+			"records_processed", job.NumberRecordsProcessed)
+		reportProgress(ctx, float64(job.NumberRecordsProcessed), 0,
+			fmt.Sprintf("Bulk job %s: %d records processed", job.State, job.NumberRecordsProcessed))
+		if job.finished() {
+			return job, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			sf.abortBulkJob(kind, id)
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+		interval = min(interval*3/2, bulkMaxPollInterval)
+	}
+}
+
+// abortBulkJob aborts a bulk job after its caller gave up on it. It runs without the
+// caller's context, which is usually the one that was cancelled.
+func (sf *SalesforceClient) abortBulkJob(kind, id string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if _, err := sf.setBulkJobState(ctx, kind, id, BulkStateAborted); err != nil {
+		sf.log().Warn("failed to abort bulk job", "job_id", id, "error", err)
+		return
+	}
+	sf.log().Info("aborted bulk job", "job_id", id)
+}
@@ -0,0 +1,528 @@
+package pkg
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Bulk API 2.0 ingest operations
+const (
+	BulkInsert     = "insert"
+	BulkUpdate     = "update"
+	BulkUpsert     = "upsert"
+	BulkDelete     = "delete"
+	BulkHardDelete = "hardDelete"
+)
+
+// BulkIngestOperations lists the supported ingest operations
+var BulkIngestOperations = []string{BulkInsert, BulkUpdate, BulkUpsert, BulkDelete, BulkHardDelete}
+
+// bulkIngestMaxUpload is the largest CSV file Bulk API 2.0 accepts in one upload
+const bulkIngestMaxUpload = 100 * 1024 * 1024
+
+// BulkIngestOptions controls a Bulk API 2.0 ingest job
+type BulkIngestOptions struct {
+	// Operation is one of BulkIngestOperations
+	Operation string
+	// ExternalIDField is the external ID field used to match records for upsert
+	ExternalIDField string
+	// ResultsDir is where the result CSV files are written, within the bulk directory; the bulk
+	// directory itself is used when empty
+	ResultsDir string
+	// PreviewRows is the number of failed rows included in the result
+	PreviewRows int
+}
+
+// SalesforceBulkResultFile describes one downloaded result CSV of an ingest job
+type SalesforceBulkResultFile struct {
+	FilePath string `json:"filePath"`
+	Records  int64  `json:"records"`
+}
+
+// SalesforceBulkIngestResult summarizes a finished Bulk API 2.0 ingest job
+type SalesforceBulkIngestResult struct {
+	JobID              string                    `json:"jobId"`
+	Object             string                    `json:"object"`
+	Operation          string                    `json:"operation"`
+	State              string                    `json:"state"`
+	ErrorMessage       string                    `json:"errorMessage,omitempty"`
+	RecordsProcessed   int64                     `json:"recordsProcessed"`
+	RecordsFailed      int64                     `json:"recordsFailed"`
+	SuccessfulResults  *SalesforceBulkResultFile `json:"successfulResults,omitempty"`
+	FailedResults      *SalesforceBulkResultFile `json:"failedResults,omitempty"`
+	UnprocessedRecords *SalesforceBulkResultFile `json:"unprocessedRecords,omitempty"`
+	FailedColumns      []string                  `json:"failedColumns,omitempty"`
+	FailedPreview      [][]string                `json:"failedPreview,omitempty"`
+}
+
+// ValidateBulkIngest checks the operation and the CSV header columns against the object's
+// describe metadata, returning every problem found
+func (sf *SalesforceClient) ValidateBulkIngest(ctx context.Context, objectType, csvPath string, options BulkIngestOptions) ([]string, error) {
+	csvPath, err := sf.bulkPath(csvPath)
+	if err != nil {
+		return nil, err
+	}
+	columns, err := readCSVHeader(csvPath)
+	if err != nil {
+		return nil, err
+	}
+
+	describe, err := sf.Describe(ctx, objectType, false)
+	if err != nil {
+		return nil, err
+	}
+	return ValidateBulkIngestColumns(describe, options.Operation, columns, options.ExternalIDField), nil
+}
+
+// ValidateBulkIngestColumns checks ingest CSV header columns against describe metadata.
+// Relationship columns such as Account.External_Id__c are checked up to the relationship name.
+func ValidateBulkIngestColumns(describe *SalesforceDescribeResponse, operation string, columns []string, externalIDField string) []string {
+	var problems []string
+
+	// Object level permissions
+	switch operation {
+	case BulkInsert:
+		if !describe.Createable {
+			problems = append(problems, fmt.Sprintf("%s is not createable", describe.Name))
+		}
+	case BulkUpdate:
+		if !describe.Updateable {
+			problems = append(problems, fmt.Sprintf("%s is not updateable", describe.Name))
+		}
+	case BulkUpsert:
+		if !describe.Createable || !describe.Updateable {
+			problems = append(problems, fmt.Sprintf("%s must be createable and updateable to upsert", describe.Name))
+		}
+	case BulkDelete, BulkHardDelete:
+		if !describe.Deletable {
+			problems = append(problems, fmt.Sprintf("%s is not deletable", describe.Name))
+		}
+	default:
+		return []string{fmt.Sprintf("unsupported operation %q (supported: %s)", operation, strings.Join(BulkIngestOperations, ", "))}
+	}
+
+	describeFields := map[string]SalesforceDescribeField{}
+	relationships := map[string]bool{}
+	for _, field := range describe.Fields {
+		describeFields[strings.ToLower(field.Name)] = field
+		if field.RelationshipName != "" {
+			relationships[strings.ToLower(field.RelationshipName)] = true
+		}
+	}
+
+	provided := map[string]bool{}
+	for _, column := range columns {
+		name := strings.ToLower(strings.TrimSpace(column))
+		if provided[name] {
+			problems = append(problems, fmt.Sprintf("column %s appears more than once", column))
+		}
+		provided[name] = true
+	}
+
+	// Delete jobs take only record IDs
+	if operation == BulkDelete || operation == BulkHardDelete {
+		if len(columns) != 1 || !provided["id"] {
+			problems = append(problems, fmt.Sprintf("%s jobs take a single Id column", operation))
+		}
+		return problems
+	}
+
+	if operation == BulkUpdate && !provided["id"] {
+		problems = append(problems, "update jobs need an Id column")
+	}
+	if operation == BulkUpsert {
+		field, ok := describeFields[strings.ToLower(externalIDField)]
+		switch {
+		case externalIDField == "":
+			problems = append(problems, "upsert jobs need an external ID field")
+		case !ok:
+			problems = append(problems, fmt.Sprintf("external ID field %s does not exist on %s", externalIDField, describe.Name))
+		case !field.ExternalID && !field.IDLookup:
+			problems = append(problems, fmt.Sprintf("%s is not an external ID field", field.Name))
+		case !provided[strings.ToLower(externalIDField)]:
+			problems = append(problems, fmt.Sprintf("upsert jobs need a %s column", field.Name))
+		}
+	}
+
+	// Field level permissions
+	for _, column := range columns {
+		name := strings.TrimSpace(column)
+		if relationship, _, ok := strings.Cut(name, "."); ok {
+			// Polymorphic relationships are written as Type:Relationship.Field
+			if _, after, found := strings.Cut(relationship, ":"); found {
+				relationship = after
+			}
+			if !relationships[strings.ToLower(relationship)] {
+				problems = append(problems, fmt.Sprintf("relationship %s in column %s does not exist on %s", relationship, name, describe.Name))
+			}
+			continue
+		}
+
+		field, ok := describeFields[strings.ToLower(name)]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("field %s does not exist on %s", name, describe.Name))
+			continue
+		}
+		if strings.EqualFold(field.Name, "Id") && operation != BulkInsert {
+			continue
+		}
+		switch operation {
+		case BulkInsert:
+			if !field.Createable {
+				problems = append(problems, fmt.Sprintf("field %s is not createable", field.Name))
+			}
+		case BulkUpdate:
+			if !field.Updateable {
+				problems = append(problems, fmt.Sprintf("field %s is not updateable", field.Name))
+			}
+		case BulkUpsert:
+			if !field.Createable && !field.Updateable {
+				problems = append(problems, fmt.Sprintf("field %s is neither createable nor updateable", field.Name))
+			}
+		}
+	}
+
+	// Required fields on insert
+	if operation == BulkInsert {
+		for _, field := range describe.Fields {
+			if field.Createable && !field.Nillable && !field.DefaultedOnCreate && field.Type != "boolean" &&
+				!provided[strings.ToLower(field.Name)] && !providedRelationship(provided, field.RelationshipName) {
+				problems = append(problems, fmt.Sprintf("required field %s is missing", field.Name))
+			}
+		}
+	}
+
+	return problems
+}
+
+// providedRelationship reports whether a column sets a lookup through its relationship name
+func providedRelationship(provided map[string]bool, relationshipName string) bool {
+	if relationshipName == "" {
+		return false
+	}
+	prefix := strings.ToLower(relationshipName) + "."
+	for column := range provided {
+		if strings.HasPrefix(column, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// BulkIngest loads a local CSV file into an object with a Bulk API 2.0 ingest job. The CSV
+// header is validated against the describe metadata before the job is created. The job is
+// created, the data uploaded and the job closed and polled until it finishes; then the
+// successful, failed and unprocessed record CSVs are downloaded.
+func (sf *SalesforceClient) BulkIngest(ctx context.Context, objectType, csvPath string, options BulkIngestOptions) (*SalesforceBulkIngestResult, error) {
+	csvPath, err := sf.bulkPath(csvPath)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := sf.bulkPath(options.ResultsDir)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(csvPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", csvPath, err)
+	}
+	if info.Size() > bulkIngestMaxUpload {
+		return nil, fmt.Errorf("%s is %d bytes; Bulk API 2.0 accepts at most %d bytes per job", csvPath, info.Size(), bulkIngestMaxUpload)
+	}
+
+	problems, err := sf.ValidateBulkIngest(ctx, objectType, csvPath, options)
+	if err != nil {
+		return nil, err
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("CSV header failed validation:\n- %s", strings.Join(problems, "\n- "))
+	}
+
+	lineEnding, err := csvLineEnding(csvPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the ingest job
+	jobsURL, err := sf.bulkJobURL(bulkJobIngest, "", "")
+	if err != nil {
+		return nil, err
+	}
+	payload := map[string]string{
+		"object":      objectType,
+		"operation":   options.Operation,
+		"contentType": "CSV",
+		"lineEnding":  lineEnding,
+	}
+	if options.Operation == BulkUpsert {
+		payload["externalIdFieldName"] = options.ExternalIDField
+	}
+	var job SalesforceBulkJob
+	if err := sf.requestJSON(ctx, "POST", jobsURL, "bulk ingest job creation", payload, &job); err != nil {
+		return nil, err
+	}
+	sf.log().InfoContext(ctx, "created bulk ingest job", "job_id", job.ID, "object", objectType, "operation", options.Operation)
+
+	// Upload the data and close the job so Salesforce starts processing it
+	if err := sf.uploadBulkIngestData(ctx, job.ID, csvPath); err != nil {
+		sf.abortBulkJob(bulkJobIngest, job.ID)
+		return nil, fmt.Errorf("bulk ingest job %s: %v", job.ID, err)
+	}
+	if _, err := sf.setBulkJobState(ctx, bulkJobIngest, job.ID, BulkStateUploadComplete); err != nil {
+		sf.abortBulkJob(bulkJobIngest, job.ID)
+		return nil, fmt.Errorf("bulk ingest job %s: %v", job.ID, err)
+	}
+
+	finished, err := sf.waitForBulkJob(ctx, bulkJobIngest, job.ID)
+	if err != nil {
+		return nil, fmt.Errorf("bulk ingest job %s: %v", job.ID, err)
+	}
+
+	result := &SalesforceBulkIngestResult{
+		JobID:            finished.ID,
+		Object:           objectType,
+		Operation:        options.Operation,
+		State:            finished.State,
+		ErrorMessage:     finished.ErrorMessage,
+		RecordsProcessed: finished.NumberRecordsProcessed,
+		RecordsFailed:    finished.NumberRecordsFailed,
+	}
+	if finished.State != BulkStateJobComplete && finished.State != BulkStateFailed {
+		return result, nil
+	}
+
+	// Download the per-record results
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create results directory: %v", err)
+	}
+	for _, resultFile := range []struct {
+		resource string
+		target   **SalesforceBulkResultFile
+	}{
+		{"successfulResults", &result.SuccessfulResults},
+		{"failedResults", &result.FailedResults},
+		{"unprocessedrecords", &result.UnprocessedRecords},
+	} {
+		path := filepath.Join(dir, fmt.Sprintf("bulk-ingest-%s-%s.csv", finished.ID, resultFile.resource))
+		records, err := sf.downloadBulkIngestResults(ctx, finished.ID, resultFile.resource, path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download %s of bulk ingest job %s: %v", resultFile.resource, finished.ID, err)
+		}
+		*resultFile.target = &SalesforceBulkResultFile{FilePath: path, Records: records}
+	}
+
+	if options.PreviewRows > 0 && result.FailedResults.Records > 0 {
+		result.FailedColumns, result.FailedPreview, err = readCSVPreview(result.FailedResults.FilePath, options.PreviewRows)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// uploadBulkIngestData uploads the CSV file as the job's data
+func (sf *SalesforceClient) uploadBulkIngestData(ctx context.Context, jobID, csvPath string) error {
+	batchesURL, err := sf.bulkJobURL(bulkJobIngest, jobID, "batches")
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(csvPath)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %v", csvPath, err)
+	}
+	defer file.Close()
+
+	req, err := http.NewRequestWithContext(ctx, "PUT", batchesURL, file)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "text/csv")
+	// Reopen the file if the upload has to be retried with a new session
+	req.GetBody = func() (io.ReadCloser, error) {
+		return os.Open(csvPath)
+	}
+	if info, err := file.Stat(); err == nil {
+		req.ContentLength = info.Size()
+	}
+
+	resp, err := sf.do(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload job data: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return apiError("bulk ingest upload", resp.StatusCode, body)
+	}
+	return nil
+}
+
+// downloadBulkIngestResults writes one result CSV of an ingest job to path and returns the
+// number of records in it. An existing file at path is never overwritten.
+func (sf *SalesforceClient) downloadBulkIngestResults(ctx context.Context, jobID, resource, path string) (int64, error) {
+	if _, err := os.Lstat(path); err == nil {
+		return 0, fmt.Errorf("result file %s already exists", path)
+	}
+
+	resultsURL, err := sf.bulkJobURL(bulkJobIngest, jobID, resource+"/")
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", resultsURL, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Accept", "text/csv")
+
+	resp, err := sf.do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to execute bulk ingest results: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return 0, apiError("bulk ingest results", resp.StatusCode, body)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0600)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create %s: %v", path, err)
+	}
+	_, err = io.Copy(file, resp.Body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return 0, fmt.Errorf("failed to write %s: %v", path, err)
+	}
+
+	return countCSVRecords(path)
+}
+
+// readCSVHeader returns the header row of a CSV file
+func readCSVHeader(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %v", path, err)
+	}
+	defer file.Close()
+
+	header, err := csv.NewReader(file).Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%s is empty", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the header of %s: %v", path, err)
+	}
+	// Drop a UTF-8 byte order mark written by spreadsheet tools
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	return header, nil
+}
+
+// csvLineEnding returns the Bulk API lineEnding value matching the file's first line
+func csvLineEnding(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %v", path, err)
+	}
+	defer file.Close()
+
+	line, err := bufio.NewReader(file).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	if strings.HasSuffix(line, "\r\n") {
+		return "CRLF", nil
+	}
+	return "LF", nil
+}
+
+// countCSVRecords returns the number of data rows in a CSV file
+func countCSVRecords(path string) (int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open %s: %v", path, err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	var rows int64
+	for {
+		_, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to read %s: %v", path, err)
+		}
+		rows++
+	}
+	if rows > 0 {
+		rows-- // header row
+	}
+	return rows, nil
+}
+
+// FormatBulkIngestAsTable formats an ingest job summary as readable text
+func FormatBulkIngestAsTable(result *SalesforceBulkIngestResult) string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("Job ID: %s\n", result.JobID))
+	buffer.WriteString(fmt.Sprintf("Object: %s\n", result.Object))
+	buffer.WriteString(fmt.Sprintf("Operation: %s\n", result.Operation))
+	buffer.WriteString(fmt.Sprintf("State: %s\n", result.State))
+	if result.ErrorMessage != "" {
+		buffer.WriteString(fmt.Sprintf("Error: %s\n", result.ErrorMessage))
+	}
+	buffer.WriteString(fmt.Sprintf("Records Processed: %d\n", result.RecordsProcessed))
+	buffer.WriteString(fmt.Sprintf("Records Failed: %d\n", result.RecordsFailed))
+
+	for _, file := range []struct {
+		label  string
+		result *SalesforceBulkResultFile
+	}{
+		{"Successful Results", result.SuccessfulResults},
+		{"Failed Results", result.FailedResults},
+		{"Unprocessed Records", result.UnprocessedRecords},
+	} {
+		if file.result != nil {
+			buffer.WriteString(fmt.Sprintf("%s: %d records in %s\n", file.label, file.result.Records, file.result.FilePath))
+		}
+	}
+
+	if len(result.FailedPreview) > 0 {
+		buffer.WriteString(fmt.Sprintf("\nFailed Records (first %d):\n", len(result.FailedPreview)))
+		buffer.WriteString(strings.Repeat("-", 50) + "\n")
+		for i, row := range result.FailedPreview {
+			buffer.WriteString(fmt.Sprintf("Record %d:\n", i+1))
+			for j, column := range result.FailedColumns {
+				if j < len(row) {
+					buffer.WriteString(fmt.Sprintf("  %s: %s\n", column, row[j]))
+				}
+			}
+			buffer.WriteString(strings.Repeat("-", 50) + "\n")
+		}
+	}
+
+	return buffer.String()
+}
+
+// FormatBulkIngestAsJSON formats an ingest job summary as JSON
+func FormatBulkIngestAsJSON(result *SalesforceBulkIngestResult) string {
+	jsonBytes, _ := json.MarshalIndent(result, "", "  ")
+	return string(jsonBytes)
+}
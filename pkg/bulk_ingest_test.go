@@ -0,0 +1,155 @@
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testIngestDescribe is the Contact metadata served by newBulkIngestTestClient
+var testIngestDescribe = &SalesforceDescribeResponse{
+	Name: "Contact", KeyPrefix: "003", Createable: true, Updateable: true, Deletable: true,
+	Fields: []SalesforceDescribeField{
+		{Name: "Id", Type: "id", IDLookup: true},
+		{Name: "LastName", Type: "string", Createable: true, Updateable: true},
+		{Name: "Email", Type: "email", Createable: true, Updateable: true, Nillable: true},
+		{Name: "External_Id__c", Type: "string", Createable: true, Updateable: true, Nillable: true, ExternalID: true},
+		{Name: "AccountId", Type: "reference", Createable: true, Updateable: true, Nillable: true,
+			ReferenceTo: []string{"Account"}, RelationshipName: "Account"},
+		{Name: "OwnerId", Type: "reference", Createable: true, Updateable: true, DefaultedOnCreate: true,
+			ReferenceTo: []string{"User"}, RelationshipName: "Owner"},
+		{Name: "CreatedDate", Type: "datetime", Nillable: true},
+	},
+}
+
+// newBulkIngestTestClient returns a client whose bulk directory is a temporary directory,
+// for a test server that serves the Contact describe and counts every other request
+func newBulkIngestTestClient(t *testing.T) (*SalesforceClient, *int) {
+	t.Helper()
+	otherRequests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" && r.URL.Path == "/services/data/v62.0/sobjects/Contact/describe" {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(testIngestDescribe)
+			return
+		}
+		otherRequests++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(server.Close)
+
+	sf := NewSalesforceClient(&OrgConfig{Name: "test"})
+	sf.auth = &SalesforceAuth{AccessToken: "token", InstanceURL: server.URL}
+	sf.version = "v62.0"
+	sf.bulkDir = t.TempDir()
+	return sf, &otherRequests
+}
+
+func TestValidateBulkIngest(t *testing.T) {
+	tests := []struct {
+		name            string
+		operation       string
+		header          string
+		externalIDField string
+		problems        []string
+	}{
+		{name: "valid insert", operation: BulkInsert, header: "LastName,Email,Account.External_Id__c"},
+		{name: "insert with lookup by ID", operation: BulkInsert, header: "LastName,AccountId"},
+		{
+			name:      "insert with unknown, read only and duplicate columns",
+			operation: BulkInsert,
+			header:    "LastName,Emial,CreatedDate,lastname",
+			problems: []string{
+				"column lastname appears more than once",
+				"field Emial does not exist on Contact",
+				"field CreatedDate is not createable",
+			},
+		},
+		{
+			name:      "insert without required field",
+			operation: BulkInsert,
+			header:    "Email",
+			problems:  []string{"required field LastName is missing"},
+		},
+		{
+			name:      "unknown relationship",
+			operation: BulkInsert,
+			header:    "LastName,Parent.External_Id__c",
+			problems:  []string{"relationship Parent in column Parent.External_Id__c does not exist on Contact"},
+		},
+		{name: "valid update", operation: BulkUpdate, header: "Id,Email"},
+		{
+			name:      "update without Id",
+			operation: BulkUpdate,
+			header:    "Email",
+			problems:  []string{"update jobs need an Id column"},
+		},
+		{name: "valid upsert", operation: BulkUpsert, header: "External_Id__c,LastName", externalIDField: "External_Id__c"},
+		{
+			name:            "upsert without the external ID column",
+			operation:       BulkUpsert,
+			header:          "LastName",
+			externalIDField: "External_Id__c",
+			problems:        []string{"upsert jobs need a External_Id__c column"},
+		},
+		{
+			name:            "upsert on a field that is not an external ID",
+			operation:       BulkUpsert,
+			header:          "Email,LastName",
+			externalIDField: "Email",
+			problems:        []string{"Email is not an external ID field"},
+		},
+		{name: "valid delete", operation: BulkDelete, header: "Id"},
+		{
+			name:      "delete with extra columns",
+			operation: BulkHardDelete,
+			header:    "Id,Email",
+			problems:  []string{"hardDelete jobs take a single Id column"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sf, otherRequests := newBulkIngestTestClient(t)
+			if err := os.WriteFile(filepath.Join(sf.bulkDir, "contacts.csv"), []byte(test.header+"\nvalue\n"), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			options := BulkIngestOptions{Operation: test.operation, ExternalIDField: test.externalIDField}
+			problems, err := sf.ValidateBulkIngest(context.Background(), "Contact", "contacts.csv", options)
+			if err != nil {
+				t.Fatalf("ValidateBulkIngest failed: %v", err)
+			}
+			if !reflect.DeepEqual(problems, test.problems) {
+				t.Errorf("problems = %q, want %q", problems, test.problems)
+			}
+			if *otherRequests != 0 {
+				t.Errorf("%d requests besides the describe, want none", *otherRequests)
+			}
+		})
+	}
+}
+
+func TestBulkIngestRejectsInvalidHeader(t *testing.T) {
+	sf, otherRequests := newBulkIngestTestClient(t)
+	path := filepath.Join(sf.bulkDir, "contacts.csv")
+	if err := os.WriteFile(path, []byte("Email,CreatedDate\na@example.com,2026-01-01\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := sf.BulkIngest(context.Background(), "Contact", path, BulkIngestOptions{Operation: BulkInsert})
+	if err == nil || !strings.Contains(err.Error(), "CSV header failed validation") ||
+		!strings.Contains(err.Error(), "field CreatedDate is not createable") ||
+		!strings.Contains(err.Error(), "required field LastName is missing") {
+		t.Errorf("got %v, want the header problems", err)
+	}
+	if *otherRequests != 0 {
+		t.Errorf("%d job requests made for an invalid header, want none", *otherRequests)
+	}
+}
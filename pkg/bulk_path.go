@@ -0,0 +1,75 @@
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultBulkDir is the directory the bulk tools read and write local files in when
+// SALESFORCE_BULK_DIR is not set
+func DefaultBulkDir() string {
+	return filepath.Join(os.TempDir(), "soql-mcp")
+}
+
+// bulkPath resolves a local file or directory used by the bulk tools. Relative paths are
+// taken from the bulk directory. The path is cleaned and its symbolic links followed, and
+// a path that ends up outside the bulk directory is rejected.
+func (sf *SalesforceClient) bulkPath(path string) (string, error) {
+	root := sf.bulkDir
+	if root == "" {
+		root = DefaultBulkDir()
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return "", fmt.Errorf("invalid bulk directory: %v", err)
+	}
+	if err := os.MkdirAll(root, 0700); err != nil {
+		return "", fmt.Errorf("failed to create bulk directory: %v", err)
+	}
+	if root, err = filepath.EvalSymlinks(root); err != nil {
+		return "", fmt.Errorf("invalid bulk directory: %v", err)
+	}
+
+	if path == "" {
+		return root, nil
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+	resolved, err := resolveSymlinks(filepath.Clean(path))
+	if err != nil {
+		return "", fmt.Errorf("invalid path %s: %v", path, err)
+	}
+
+	rel, err := filepath.Rel(root, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path %s is outside the bulk directory %s", path, root)
+	}
+	return resolved, nil
+}
+
+// resolveSymlinks follows the symbolic links of the longest existing prefix of a clean,
+// absolute path; the rest of the path does not exist yet and is kept as written
+func resolveSymlinks(path string) (string, error) {
+	var missing []string
+	for {
+		resolved, err := filepath.EvalSymlinks(path)
+		if err == nil {
+			for i := len(missing) - 1; i >= 0; i-- {
+				resolved = filepath.Join(resolved, missing[i])
+			}
+			return resolved, nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return "", err
+		}
+		missing = append(missing, filepath.Base(path))
+		path = parent
+	}
+}
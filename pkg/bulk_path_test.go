@@ -0,0 +1,60 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBulkPath(t *testing.T) {
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	outside := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(root, "escape")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(root, "exports"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(root, "exports"), filepath.Join(root, "latest")); err != nil {
+		t.Fatal(err)
+	}
+	sf := &SalesforceClient{bulkDir: root}
+
+	tests := []struct {
+		name string
+		path string
+		want string
+		err  string
+	}{
+		{name: "bulk directory", path: "", want: root},
+		{name: "relative file", path: "accounts.csv", want: filepath.Join(root, "accounts.csv")},
+		{name: "absolute file", path: filepath.Join(root, "exports", "a.csv"), want: filepath.Join(root, "exports", "a.csv")},
+		{name: "missing directories", path: "new/dir/a.csv", want: filepath.Join(root, "new", "dir", "a.csv")},
+		{name: "symlink inside", path: "latest/a.csv", want: filepath.Join(root, "exports", "a.csv")},
+		{name: "parent directory", path: "../a.csv", err: "outside the bulk directory"},
+		{name: "cleaned back inside", path: "exports/../a.csv", want: filepath.Join(root, "a.csv")},
+		{name: "absolute outside", path: filepath.Join(outside, "a.csv"), err: "outside the bulk directory"},
+		{name: "symlink outside", path: "escape/a.csv", err: "outside the bulk directory"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := sf.bulkPath(test.path)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("bulkPath(%q) = %q, %v, want error containing %q", test.path, got, err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("bulkPath(%q) failed: %v", test.path, err)
+			}
+			if got != test.want {
+				t.Errorf("bulkPath(%q) = %q, want %q", test.path, got, test.want)
+			}
+		})
+	}
+}
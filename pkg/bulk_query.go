@@ -0,0 +1,281 @@
+package pkg
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DefaultBulkPreviewRows is the number of rows shown in a bulk query preview
+const DefaultBulkPreviewRows = 10
+
+// bulkQueryChunkSize is the maximum number of records requested per result chunk
+const bulkQueryChunkSize = 50000
+
+// BulkQueryOptions controls a Bulk API 2.0 query
+type BulkQueryOptions struct {
+	// IncludeDeleted runs a queryAll job that also returns deleted and archived records
+	IncludeDeleted bool
+	// OutputPath is the CSV file results are written to, within the bulk directory; a file
+	// named after the job is used when empty. An existing file is never overwritten.
+	OutputPath string
+	// PreviewRows is the number of result rows included in the preview
+	PreviewRows int
+}
+
+// SalesforceBulkQueryResult summarizes a finished Bulk API 2.0 query
+type SalesforceBulkQueryResult struct {
+	JobID        string     `json:"jobId"`
+	State        string     `json:"state"`
+	Operation    string     `json:"operation"`
+	RecordCount  int64      `json:"recordCount"`
+	Chunks       int        `json:"chunks"`
+	FilePath     string     `json:"filePath"`
+	ErrorMessage string     `json:"errorMessage,omitempty"`
+	Columns      []string   `json:"columns,omitempty"`
+	Preview      [][]string `json:"preview,omitempty"`
+	PolicyNotes  []string   `json:"policyNotes,omitempty"`
+}
+
+// BulkQuery runs a SOQL query as a Bulk API 2.0 job: it creates the job, polls it until it
+// finishes and streams the CSV result chunks to a local file
+func (sf *SalesforceClient) BulkQuery(ctx context.Context, query string, options BulkQueryOptions) (*SalesforceBulkQueryResult, error) {
+	query, policyNotes, err := sf.applyQueryPolicy(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	// Refuse an output file outside the bulk directory or an existing one before creating a job
+	path := ""
+	if options.OutputPath != "" {
+		if path, err = sf.bulkPath(options.OutputPath); err != nil {
+			return nil, err
+		}
+		if _, err := os.Lstat(path); err == nil {
+			return nil, fmt.Errorf("output file %s already exists", path)
+		}
+	}
+
+	operation := "query"
+	if options.IncludeDeleted {
+		operation = "queryAll"
+	}
+
+	// Create the query job
+	jobsURL, err := sf.bulkJobURL(bulkJobQuery, "", "")
+	if err != nil {
+		return nil, err
+	}
+	payload := map[string]string{
+		"operation":   operation,
+		"query":       query,
+		"contentType": "CSV",
+		"lineEnding":  "LF",
+	}
+	var job SalesforceBulkJob
+	if err := sf.requestJSON(ctx, "POST", jobsURL, "bulk query job creation", payload, &job); err != nil {
+		return nil, err
+	}
+	sf.log().InfoContext(ctx, "created bulk query job", "job_id", job.ID, "operation", operation)
+
+	// Wait for Salesforce to finish the job
+	finished, err := sf.waitForBulkJob(ctx, bulkJobQuery, job.ID)
+	if err != nil {
+		return nil, fmt.Errorf("bulk query job %s: %v", job.ID, err)
+	}
+
+	result := &SalesforceBulkQueryResult{
+		JobID:        finished.ID,
+		State:        finished.State,
+		Operation:    operation,
+		ErrorMessage: finished.ErrorMessage,
+		PolicyNotes:  policyNotes,
+	}
+	if finished.State != BulkStateJobComplete {
+		return result, nil
+	}
+
+	// Stream the result chunks to the output file
+	if path == "" {
+		if path, err = sf.bulkPath(fmt.Sprintf("bulk-query-%s.csv", finished.ID)); err != nil {
+			return nil, err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %v", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output file: %v", err)
+	}
+
+	result.FilePath = path
+	result.RecordCount, result.Chunks, err = sf.downloadBulkQueryResults(ctx, finished, file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return nil, fmt.Errorf("failed to download results of bulk query job %s: %v", finished.ID, err)
+	}
+
+	previewRows := options.PreviewRows
+	if previewRows < 0 {
+		previewRows = 0
+	}
+	if result.Columns, result.Preview, err = readCSVPreview(path, previewRows); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// downloadBulkQueryResults writes every result chunk of a finished query job to out,
+// following the Sforce-Locator header. Each chunk repeats the CSV header row, which is
+// written only once.
+func (sf *SalesforceClient) downloadBulkQueryResults(ctx context.Context, job *SalesforceBulkJob, out io.Writer) (int64, int, error) {
+	resultsURL, err := sf.bulkJobURL(bulkJobQuery, job.ID, "results")
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var records int64
+	chunks := 0
+	locator := ""
+	for {
+		params := url.Values{}
+		params.Set("maxRecords", strconv.Itoa(bulkQueryChunkSize))
+		if locator != "" {
+			params.Set("locator", locator)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "GET", resultsURL+"?"+params.Encode(), nil)
+		if err != nil {
+			return records, chunks, fmt.Errorf("failed to create request: %v", err)
+		}
+		req.Header.Set("Accept", "text/csv")
+
+		resp, err := sf.do(req)
+		if err != nil {
+			return records, chunks, fmt.Errorf("failed to execute bulk query results: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return records, chunks, apiError("bulk query results", resp.StatusCode, body)
+		}
+
+		body := bufio.NewReader(resp.Body)
+		if chunks > 0 {
+			// Skip the repeated header row
+			if _, err := body.ReadString('\n'); err != nil && err != io.EOF {
+				resp.Body.Close()
+				return records, chunks, fmt.Errorf("failed to read bulk query results: %v", err)
+			}
+		}
+		_, err = io.Copy(out, body)
+		resp.Body.Close()
+		if err != nil {
+			return records, chunks, fmt.Errorf("failed to write bulk query results: %v", err)
+		}
+
+		chunks++
+		if count, err := strconv.ParseInt(resp.Header.Get("Sforce-NumberOfRecords"), 10, 64); err == nil {
+			records += count
+		}
+		reportProgress(ctx, float64(records), float64(job.NumberRecordsProcessed),
+			fmt.Sprintf("Downloaded %d of %d records", records, job.NumberRecordsProcessed))
+
+		locator = resp.Header.Get("Sforce-Locator")
+		if locator == "" || locator == "null" {
+			break
+		}
+	}
+	return records, chunks, nil
+}
+
+// readCSVPreview returns the header row and up to rows data rows of a CSV file
+func readCSVPreview(path string, rows int) ([]string, [][]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open %s: %v", path, err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	columns, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+
+	var preview [][]string
+	for len(preview) < rows {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read %s: %v", path, err)
+		}
+		preview = append(preview, row)
+	}
+	return columns, preview, nil
+}
+
+// FormatBulkQueryAsTable formats a bulk query summary and its preview rows as a readable table
+func FormatBulkQueryAsTable(result *SalesforceBulkQueryResult) string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("Job ID: %s\n", result.JobID))
+	buffer.WriteString(fmt.Sprintf("Operation: %s\n", result.Operation))
+	buffer.WriteString(fmt.Sprintf("State: %s\n", result.State))
+	if result.ErrorMessage != "" {
+		buffer.WriteString(fmt.Sprintf("Error: %s\n", result.ErrorMessage))
+	}
+	for _, note := range result.PolicyNotes {
+		buffer.WriteString(fmt.Sprintf("Query policy: %s\n", note))
+	}
+	if result.FilePath == "" {
+		return buffer.String()
+	}
+	buffer.WriteString(fmt.Sprintf("Records: %d\n", result.RecordCount))
+	buffer.WriteString(fmt.Sprintf("Chunks: %d\n", result.Chunks))
+	buffer.WriteString(fmt.Sprintf("File: %s\n", result.FilePath))
+
+	if len(result.Columns) == 0 {
+		return buffer.String()
+	}
+	buffer.WriteString(fmt.Sprintf("\nPreview (first %d rows):\n", len(result.Preview)))
+	buffer.WriteString(strings.Repeat("-", 50) + "\n")
+	for i, row := range result.Preview {
+		buffer.WriteString(fmt.Sprintf("Record %d:\n", i+1))
+		for j, column := range result.Columns {
+			value := ""
+			if j < len(row) {
+				value = row[j]
+			}
+			buffer.WriteString(fmt.Sprintf("  %s: %s\n", column, value))
+		}
+		buffer.WriteString(strings.Repeat("-", 50) + "\n")
+	}
+
+	return buffer.String()
+}
+
+// FormatBulkQueryAsJSON formats a bulk query summary as JSON
+func FormatBulkQueryAsJSON(result *SalesforceBulkQueryResult) string {
+	jsonBytes, _ := json.MarshalIndent(result, "", "  ")
+	return string(jsonBytes)
+}
@@ -5,13 +5,37 @@ import (
 	"time"
 )
 
-// ClientManager manages a singleton Salesforce client with connection reuse
+// ClientManager manages one cached Salesforce client per org profile with connection reuse
 type ClientManager struct {
-	client      *SalesforceClient
-	config      *Config
-	lastAuth    time.Time
-	tokenExpiry time.Duration
-	mutex       sync.RWMutex
+	config        *Config
+	orgs          map[string]*orgClient
+	describeCache *DescribeCache
+	mutex         sync.Mutex
+}
+
+// orgClient holds the cached client and token lifecycle for one org profile
+type orgClient struct {
+	config    *OrgConfig
+	client    *SalesforceClient
+	lastAuth  time.Time
+	lastError error
+	mutex     sync.Mutex
+}
+
+// OrgStatus describes an org profile and its connection state
+type OrgStatus struct {
+	Name        string       `json:"name"`
+	Default     bool         `json:"default"`
+	URL         string       `json:"url"`
+	Username    string       `json:"username,omitempty"`
+	AuthFlow    string       `json:"authFlow"`
+	Connected   bool         `json:"connected"`
+	InstanceURL string       `json:"instanceUrl,omitempty"`
+	APIVersion  string       `json:"apiVersion,omitempty"`
+	QueryPolicy *QueryPolicy `json:"queryPolicy,omitempty"`
+	LastAuth    *time.Time   `json:"lastAuth,omitempty"`
+	ExpiresAt   *time.Time   `json:"expiresAt,omitempty"`
+	LastError   string       `json:"lastError,omitempty"`
 }
 
 var (
@@ -23,45 +47,139 @@ var (
 func GetClientManager(config *Config) *ClientManager {
 	once.Do(func() {
 		instance = &ClientManager{
-			config:      config,
-			tokenExpiry: 2 * time.Hour, // Salesforce tokens typically expire in 2 hours
+			config:        config,
+			orgs:          map[string]*orgClient{},
+			describeCache: NewDescribeCache(config.DescribeCacheDir),
 		}
 	})
 	return instance
 }
 
-// GetClient returns an authenticated Salesforce client, reusing connection when possible
-func (cm *ClientManager) GetClient() (*SalesforceClient, error) {
+// getOrg returns the cache entry for an org profile, creating it on first use
+func (cm *ClientManager) getOrg(name string) (*orgClient, error) {
+	orgConfig, err := cm.config.GetOrg(name)
+	if err != nil {
+		return nil, err
+	}
+
 	cm.mutex.Lock()
 	defer cm.mutex.Unlock()
 
-	// Check if we need to authenticate or re-authenticate
-	if cm.client == nil || cm.needsReauth() {
-		if cm.client == nil {
-			cm.client = NewSalesforceClient(cm.config)
-		}
+	oc, ok := cm.orgs[orgConfig.Name]
+	if !ok {
+		oc = &orgClient{config: orgConfig}
+		cm.orgs[orgConfig.Name] = oc
+	}
+	return oc, nil
+}
+
+// GetClient returns an authenticated Salesforce client for the named org, reusing the
+// connection when possible. An empty name selects the default org.
+func (cm *ClientManager) GetClient(org string) (*SalesforceClient, error) {
+	oc, err := cm.getOrg(org)
+	if err != nil {
+		return nil, err
+	}
 
-		if err := cm.client.Authenticate(); err != nil {
+	oc.mutex.Lock()
+	defer oc.mutex.Unlock()
+
+	if oc.client == nil {
+		oc.client = NewSalesforceClient(oc.config)
+		oc.client.reauth = oc.refresh
+		oc.client.describeCache = cm.describeCache
+		oc.client.bulkDir = cm.config.BulkDir
+	}
+
+	// Check if we need to authenticate or re-authenticate
+	if oc.client.needsRefresh() {
+		if err := oc.authenticate(); err != nil {
 			return nil, err
 		}
+	}
+
+	return oc.client, nil
+}
+
+// authenticate obtains a new access token; the caller must hold oc.mutex
+func (oc *orgClient) authenticate() error {
+	if err := oc.client.Authenticate(); err != nil {
+		Logger().Warn("authentication failed", "org", oc.config.Name, "error", err)
+		oc.lastError = err
+		return err
+	}
+	oc.lastAuth = time.Now()
+	oc.lastError = nil
+	return nil
+}
 
-		cm.lastAuth = time.Now()
+// refresh re-authenticates after Salesforce rejected staleToken. Concurrent requests that
+// hit the same expired session share a single refresh.
+func (oc *orgClient) refresh(staleToken string) error {
+	oc.mutex.Lock()
+	defer oc.mutex.Unlock()
+
+	if auth, err := oc.client.session(); err == nil && auth.AccessToken != staleToken {
+		return nil
 	}
+	return oc.authenticate()
+}
 
-	return cm.client, nil
+// ListOrgs returns every configured org profile with its connection state
+func (cm *ClientManager) ListOrgs() []OrgStatus {
+	var statuses []OrgStatus
+	for _, name := range cm.config.OrgNames() {
+		orgConfig := cm.config.Orgs[name]
+		status := OrgStatus{
+			Name:     name,
+			Default:  name == cm.config.DefaultOrg,
+			URL:      orgConfig.SalesforceURL,
+			Username: orgConfig.SalesforceUsername,
+			AuthFlow: orgConfig.AuthFlowName(),
+		}
+		if orgConfig.QueryPolicy.Enabled() {
+			policy := orgConfig.QueryPolicy
+			status.QueryPolicy = &policy
+		}
+
+		cm.mutex.Lock()
+		oc, ok := cm.orgs[name]
+		cm.mutex.Unlock()
+
+		if ok {
+			oc.mutex.Lock()
+			if oc.client != nil && !oc.client.needsRefresh() {
+				if auth, err := oc.client.session(); err == nil {
+					status.Connected = true
+					status.InstanceURL = auth.InstanceURL
+					status.APIVersion = oc.client.APIVersion()
+					expiresAt := oc.client.ExpiresAt()
+					status.ExpiresAt = &expiresAt
+				}
+			}
+			if !oc.lastAuth.IsZero() {
+				lastAuth := oc.lastAuth
+				status.LastAuth = &lastAuth
+			}
+			if oc.lastError != nil {
+				status.LastError = oc.lastError.Error()
+			}
+			oc.mutex.Unlock()
+		}
+
+		statuses = append(statuses, status)
+	}
+	return statuses
 }
 
-// needsReauth checks if re-authentication is needed based on token expiry
-func (cm *ClientManager) needsReauth() bool {
-	// Re-authenticate if it's been more than 90% of token expiry time
-	// This provides a buffer to avoid token expiry during requests
-	return time.Since(cm.lastAuth) > time.Duration(float64(cm.tokenExpiry)*0.9)
+// DescribeCacheStats returns the describe cache statistics
+func (cm *ClientManager) DescribeCacheStats() DescribeCacheStats {
+	return cm.describeCache.Stats()
 }
 
-// Reset clears the cached client (useful for testing or configuration changes)
+// Reset clears the cached clients (useful for testing or configuration changes)
 func (cm *ClientManager) Reset() {
 	cm.mutex.Lock()
 	defer cm.mutex.Unlock()
-	cm.client = nil
-	cm.lastAuth = time.Time{}
+	cm.orgs = map[string]*orgClient{}
 }
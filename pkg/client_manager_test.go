@@ -0,0 +1,158 @@
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestClientManager returns a client manager for a single org that authenticates with
+// the password flow against a test server
+func newTestClientManager(serverURL, apiVersion string) *ClientManager {
+	org := &OrgConfig{
+		Name:                     DefaultOrgName,
+		SalesforceURL:            serverURL,
+		SalesforceClientID:       "id",
+		SalesforceClientSecret:   "secret",
+		SalesforceUsername:       "user@example.com",
+		SalesforcePassword:       "password",
+		SalesforceAPIVersion:     apiVersion,
+		SalesforceTimeout:        5 * time.Second,
+		SalesforceSessionTimeout: time.Hour,
+	}
+	return &ClientManager{
+		config: &Config{DefaultOrg: DefaultOrgName, Orgs: map[string]*OrgConfig{DefaultOrgName: org}},
+		orgs:   map[string]*orgClient{},
+	}
+}
+
+func TestGetClientSessionRejectedWhileAuthenticating(t *testing.T) {
+	tests := []struct {
+		name string
+		// status and body are the API version discovery response
+		status int
+		body   string
+	}{
+		{name: "unauthorized", status: http.StatusUnauthorized, body: `[{"message":"Session expired or invalid","errorCode":"INVALID_SESSION_ID"}]`},
+		{name: "invalid session", status: http.StatusForbidden, body: `[{"message":"Session expired or invalid","errorCode":"INVALID_SESSION_ID"}]`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var server *httptest.Server
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				if strings.HasSuffix(r.URL.Path, "/oauth2/token") {
+					json.NewEncoder(w).Encode(SalesforceAuth{AccessToken: "token", InstanceURL: server.URL})
+					return
+				}
+				w.WriteHeader(test.status)
+				w.Write([]byte(test.body))
+			}))
+			defer server.Close()
+
+			cm := newTestClientManager(server.URL, APIVersionLatest)
+
+			done := make(chan error, 1)
+			go func() {
+				_, err := cm.GetClient("")
+				done <- err
+			}()
+			select {
+			case err := <-done:
+				if err == nil {
+					t.Fatal("GetClient succeeded, want an API version discovery error")
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("GetClient deadlocked")
+			}
+		})
+	}
+}
+
+func TestClientReauthenticatesOnInvalidSession(t *testing.T) {
+	const invalidSession = `[{"message":"Session expired or invalid","errorCode":"INVALID_SESSION_ID"}]`
+	tests := []struct {
+		name string
+		// status is the response to a request with a rejected token
+		status int
+		create bool
+		// rejectAll also rejects the token issued by re-authentication
+		rejectAll bool
+	}{
+		{name: "unauthorized", status: http.StatusUnauthorized},
+		{name: "invalid session error code", status: http.StatusForbidden},
+		{name: "request body replayed", status: http.StatusUnauthorized, create: true},
+		{name: "retried once", status: http.StatusUnauthorized, rejectAll: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var server *httptest.Server
+			var mutex sync.Mutex
+			tokens, requests := 0, 0
+			var bodies []string
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mutex.Lock()
+				defer mutex.Unlock()
+				w.Header().Set("Content-Type", "application/json")
+				if strings.HasSuffix(r.URL.Path, "/oauth2/token") {
+					tokens++
+					json.NewEncoder(w).Encode(SalesforceAuth{AccessToken: fmt.Sprintf("token-%d", tokens), InstanceURL: server.URL})
+					return
+				}
+				if strings.HasSuffix(r.URL.Path, "/oauth2/introspect") {
+					w.Write([]byte(`{"active":true}`))
+					return
+				}
+
+				requests++
+				body, _ := io.ReadAll(r.Body)
+				bodies = append(bodies, string(body))
+				if r.Header.Get("Authorization") == "Bearer token-1" || test.rejectAll {
+					w.WriteHeader(test.status)
+					w.Write([]byte(invalidSession))
+					return
+				}
+				if r.Method == "POST" {
+					w.WriteHeader(http.StatusCreated)
+					w.Write([]byte(`{"id":"001000000000001","success":true,"errors":[]}`))
+					return
+				}
+				w.Write([]byte(`{"totalSize":1,"done":true,"records":[{"Id":"001000000000001"}]}`))
+			}))
+			defer server.Close()
+
+			sf, err := newTestClientManager(server.URL, "62.0").GetClient("")
+			if err != nil {
+				t.Fatalf("GetClient failed: %v", err)
+			}
+			if test.create {
+				_, err = sf.CreateRecord(context.Background(), "Account", map[string]interface{}{"Name": "Acme"})
+			} else {
+				_, err = sf.Query(context.Background(), "SELECT Id FROM Account", 0)
+			}
+
+			if test.rejectAll {
+				if err == nil || !strings.Contains(err.Error(), "INVALID_SESSION_ID") {
+					t.Errorf("got %v, want the rejected session error", err)
+				}
+			} else if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			if tokens != 2 || requests != 2 {
+				t.Errorf("tokens issued, API requests = %d, %d, want 2, 2", tokens, requests)
+			}
+			if test.create && (bodies[0] != bodies[1] || !strings.Contains(bodies[1], "Acme")) {
+				t.Errorf("retried body = %q, want the original body %q", bodies[1], bodies[0])
+			}
+		})
+	}
+}
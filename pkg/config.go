@@ -3,7 +3,11 @@ package pkg
 import (
 	"fmt"
 	"os"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
 // Build-time variables set via ldflags
@@ -13,6 +17,16 @@ var (
 	BuildDate = "" // Default build time
 )
 
+// Supported values for MCP_TRANSPORT and --transport
+const (
+	TransportStdio = "stdio"
+	TransportSSE   = "sse"
+	TransportHTTP  = "http"
+)
+
+// DefaultOrgName is the profile name used when SALESFORCE_ORGS is not set
+const DefaultOrgName = "default"
+
 // Config holds all configuration values
 type Config struct {
 	ServerName    string
@@ -22,6 +36,29 @@ type Config struct {
 	ResourcePath  string
 	Debug         bool
 	LogLevel      string
+	LogFormat     string
+	LogFile       string
+	// Transport configuration
+	Transport     string
+	ListenAddr    string
+	AuthToken     string
+	AuthTokenFile string
+	// Describe cache directory; describes are cached in memory only when empty
+	DescribeCacheDir string
+	// AllowWrites registers the record write tools
+	AllowWrites bool
+	// BulkDir is the only directory the bulk tools read and write local files in
+	BulkDir string
+	// ConfigFile is the config file settings were read from, if any
+	ConfigFile string
+	// Salesforce org profiles
+	DefaultOrg string
+	Orgs       map[string]*OrgConfig
+}
+
+// OrgConfig holds the connection settings for one Salesforce org profile
+type OrgConfig struct {
+	Name string
 	// Salesforce configuration
 	SalesforceURL           string
 	SalesforceClientID      string
@@ -29,34 +66,255 @@ type Config struct {
 	SalesforceUsername      string
 	SalesforcePassword      string
 	SalesforceSecurityToken string
+	// Salesforce auth flow configuration
+	SalesforceAuthFlow       string
+	SalesforcePrivateKeyPath string
+	SalesforceJWTAudience    string
+	SalesforceRedirectURI    string
+	SalesforceTokenFile      string
+	SalesforceOrgAlias       string
+	SalesforceSessionTimeout time.Duration
+	SalesforceTimeout        time.Duration
+	SalesforceAPIVersion     string
+	// QueryPolicy restricts the SOQL queries run against the org
+	QueryPolicy QueryPolicy
+}
+
+// LoadOptions controls where LoadConfig reads settings from
+type LoadOptions struct {
+	// ConfigFile is the YAML config file to read. When empty, MCP_CONFIG_FILE and then
+	// the default XDG path are tried, and a missing file is not an error.
+	ConfigFile string
+	// Flags holds command line values keyed by the environment variable they override
+	Flags map[string]string
+}
+
+// settings resolves configuration keys, named after their environment variables, from
+// command line flags, then the environment, then the config file
+type settings struct {
+	flags map[string]string
+	file  map[string]string
+}
+
+// get returns the value for key, or defaultValue if no source sets it
+func (s *settings) get(key, defaultValue string) string {
+	return s.getFirst([]string{key}, defaultValue)
 }
 
-// LoadConfig loads configuration from environment variables
-func LoadConfig() *Config {
+// getFirst returns the value of the first key set in the first source that sets any of
+// them, or defaultValue. A source of higher precedence wins even when it only sets a later
+// key.
+func (s *settings) getFirst(keys []string, defaultValue string) string {
+	sources := []func(string) string{
+		func(key string) string { return s.flags[key] },
+		os.Getenv,
+		func(key string) string { return s.file[key] },
+	}
+	for _, source := range sources {
+		for _, key := range keys {
+			if value := source(key); value != "" {
+				return value
+			}
+		}
+	}
+	return defaultValue
+}
+
+// getBool returns the boolean value for key, or defaultValue if it is unset or invalid
+func (s *settings) getBool(key string, defaultValue bool) bool {
+	if parsed, err := strconv.ParseBool(s.get(key, "")); err == nil {
+		return parsed
+	}
+	return defaultValue
+}
+
+// LoadConfig loads configuration from command line flags, environment variables and the
+// config file, in that order of precedence
+func LoadConfig(options LoadOptions) (*Config, error) {
+	path, required := options.ConfigFile, options.ConfigFile != ""
+	if !required {
+		path, required = os.Getenv("MCP_CONFIG_FILE"), os.Getenv("MCP_CONFIG_FILE") != ""
+	}
+	if !required {
+		path = DefaultConfigFile()
+	}
+	file, err := readConfigFile(path, required)
+	if err != nil {
+		return nil, err
+	}
+	s := &settings{flags: options.Flags, file: file}
+
 	config := &Config{
-		ServerName:    GetEnvWithDefault("MCP_SERVER_NAME", "Demo 🚀"),
-		ServerVersion: GetEnvWithDefault("MCP_SERVER_VERSION", Version),
-		Commit:        GetEnvWithDefault("MCP_COMMIT", Commit),
-		BuildDate:     GetEnvWithDefault("MCP_BUILD_DATE", BuildDate),
-		ResourcePath:  GetEnvWithDefault("MCP_RESOURCE_PATH", ""),
-		Debug:         getEnvBool("MCP_DEBUG", false),
-		LogLevel:      GetEnvWithDefault("MCP_LOG_LEVEL", "info"),
-		// Salesforce configuration
-		SalesforceURL:           GetEnvWithDefault("SALESFORCE_URL", "https://login.salesforce.com"),
-		SalesforceClientID:      GetEnvWithDefault("SALESFORCE_CLIENT_ID", ""),
-		SalesforceClientSecret:  GetEnvWithDefault("SALESFORCE_CLIENT_SECRET", ""),
-		SalesforceUsername:      GetEnvWithDefault("SALESFORCE_USERNAME", ""),
-		SalesforcePassword:      GetEnvWithDefault("SALESFORCE_PASSWORD", ""),
-		SalesforceSecurityToken: GetEnvWithDefault("SALESFORCE_SECURITY_TOKEN", ""),
+		ServerName:    s.get("MCP_SERVER_NAME", "Demo 🚀"),
+		ServerVersion: s.get("MCP_SERVER_VERSION", Version),
+		Commit:        s.get("MCP_COMMIT", Commit),
+		BuildDate:     s.get("MCP_BUILD_DATE", BuildDate),
+		ResourcePath:  s.get("MCP_RESOURCE_PATH", ""),
+		Debug:         s.getBool("MCP_DEBUG", false),
+		LogLevel:      s.get("MCP_LOG_LEVEL", "info"),
+		LogFormat:     s.get("MCP_LOG_FORMAT", LogFormatText),
+		LogFile:       s.get("MCP_LOG_FILE", ""),
+		Orgs:          map[string]*OrgConfig{},
+		// Transport configuration
+		Transport:     s.get("MCP_TRANSPORT", TransportStdio),
+		ListenAddr:    s.get("MCP_LISTEN", "127.0.0.1:8080"),
+		AuthToken:     s.get("MCP_AUTH_TOKEN", ""),
+		AuthTokenFile: s.get("MCP_AUTH_TOKEN_FILE", ""),
+		// Describe cache configuration
+		DescribeCacheDir: s.get("SALESFORCE_DESCRIBE_CACHE_DIR", ""),
+		AllowWrites:      s.getBool("SALESFORCE_ALLOW_WRITES", false),
+		BulkDir:          s.get("SALESFORCE_BULK_DIR", DefaultBulkDir()),
+	}
+	if required || len(file) > 0 {
+		config.ConfigFile = path
 	}
 
+	// Load org profiles, falling back to a single profile from the unprefixed settings
+	orgNames := splitList(s.get("SALESFORCE_ORGS", ""))
+	if len(orgNames) == 0 {
+		orgNames = []string{DefaultOrgName}
+	}
+	for _, name := range orgNames {
+		org, err := loadOrgConfig(s, name)
+		if err != nil {
+			return nil, fmt.Errorf("configuration error: org %s: %v", name, err)
+		}
+		config.Orgs[name] = org
+	}
+	config.DefaultOrg = s.get("SALESFORCE_DEFAULT_ORG", orgNames[0])
+
 	// Validate configuration
 	if err := config.Validate(); err != nil {
-		fmt.Printf("Configuration error: %v\n", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("configuration error: %v", err)
+	}
+
+	return config, nil
+}
+
+// loadOrgConfig loads one org profile. Each SALESFORCE_<KEY> setting can be overridden
+// per profile with SALESFORCE_<NAME>_<KEY>, e.g. SALESFORCE_UAT_USERNAME. This includes
+// the default profile, which a config file sets under orgs.default. The sources keep their
+// precedence: the profile key only wins over the shared key set in the same source.
+func loadOrgConfig(s *settings, name string) (*OrgConfig, error) {
+	get := func(key, defaultValue string) string {
+		return s.getFirst([]string{"SALESFORCE_" + envName(name) + "_" + key, "SALESFORCE_" + key}, defaultValue)
+	}
+
+	maxLimit := 0
+	if value := get("QUERY_MAX_LIMIT", ""); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid query max limit %q: %v", value, err)
+		}
+		maxLimit = parsed
+	}
+
+	return &OrgConfig{
+		Name: name,
+		// Salesforce configuration
+		SalesforceURL:           get("URL", "https://login.salesforce.com"),
+		SalesforceClientID:      get("CLIENT_ID", ""),
+		SalesforceClientSecret:  get("CLIENT_SECRET", ""),
+		SalesforceUsername:      get("USERNAME", ""),
+		SalesforcePassword:      get("PASSWORD", ""),
+		SalesforceSecurityToken: get("SECURITY_TOKEN", ""),
+		// Salesforce auth flow configuration
+		SalesforceAuthFlow:       get("AUTH_FLOW", ""),
+		SalesforcePrivateKeyPath: get("PRIVATE_KEY_PATH", ""),
+		SalesforceJWTAudience:    get("JWT_AUDIENCE", ""),
+		SalesforceRedirectURI:    get("REDIRECT_URI", "http://localhost:1717/OauthRedirect"),
+		SalesforceTokenFile:      get("TOKEN_FILE", DefaultTokenFile()),
+		SalesforceOrgAlias:       get("ORG_ALIAS", ""),
+		SalesforceSessionTimeout: parseDuration(get("SESSION_TIMEOUT", ""), 2*time.Hour),
+		SalesforceTimeout:        parseDuration(get("TIMEOUT", ""), 60*time.Second),
+		SalesforceAPIVersion:     get("API_VERSION", DefaultAPIVersion),
+		QueryPolicy: QueryPolicy{
+			MaxLimit:     maxLimit,
+			DenyObjects:  splitList(get("QUERY_DENY_OBJECTS", "")),
+			DenyFields:   splitList(get("QUERY_DENY_FIELDS", "")),
+			DeniedFields: get("QUERY_DENIED_FIELDS", DeniedFieldsBlock),
+		},
+	}, nil
+}
+
+// currentConfig is the configuration loaded at startup and shared by the tool handlers
+var (
+	currentConfig *Config
+	configMutex   sync.RWMutex
+)
+
+// SetConfig makes config the configuration returned by CurrentConfig
+func SetConfig(config *Config) {
+	configMutex.Lock()
+	defer configMutex.Unlock()
+	currentConfig = config
+}
+
+// CurrentConfig returns the configuration loaded at startup
+func CurrentConfig() *Config {
+	configMutex.RLock()
+	defer configMutex.RUnlock()
+	return currentConfig
+}
+
+// envName converts a profile name to the form used in environment variable names
+func envName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		if r >= 'a' && r <= 'z' {
+			return r - 'a' + 'A'
+		}
+		return '_'
+	}, name)
+}
+
+// parseDuration parses a duration such as "30m", returning defaultValue when it is empty or invalid
+func parseDuration(value string, defaultValue time.Duration) time.Duration {
+	if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+		return parsed
 	}
+	return defaultValue
+}
+
+// joinSorted sorts names and joins them with commas
+func joinSorted(names []string) string {
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
 
-	return config
+// splitList splits a comma separated list, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// GetOrg returns the named org profile, or the default profile when name is empty
+func (c *Config) GetOrg(name string) (*OrgConfig, error) {
+	if name == "" {
+		name = c.DefaultOrg
+	}
+	org, ok := c.Orgs[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown org %q (configured: %s)", name, strings.Join(c.OrgNames(), ", "))
+	}
+	return org, nil
+}
+
+// OrgNames returns the configured org profile names in sorted order
+func (c *Config) OrgNames() []string {
+	names := make([]string, 0, len(c.Orgs))
+	for name := range c.Orgs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
 // Validate checks if the configuration is valid
@@ -70,21 +328,83 @@ func (c *Config) Validate() error {
 	if c.ResourcePath == "" {
 		return fmt.Errorf("resource path cannot be empty")
 	}
+	if _, err := parseLogLevel(c.LogLevel); err != nil {
+		return err
+	}
+	if c.LogFormat != LogFormatText && c.LogFormat != LogFormatJSON {
+		return fmt.Errorf("unsupported log format %q (supported: text, json)", c.LogFormat)
+	}
+	switch c.Transport {
+	case TransportStdio, TransportSSE, TransportHTTP:
+	default:
+		return fmt.Errorf("unsupported transport %q (supported: stdio, sse, http)", c.Transport)
+	}
+	for _, name := range c.OrgNames() {
+		if _, err := normalizeAPIVersion(c.Orgs[name].SalesforceAPIVersion); err != nil {
+			return fmt.Errorf("org %s: %v", name, err)
+		}
+		if err := c.Orgs[name].QueryPolicy.Validate(); err != nil {
+			return fmt.Errorf("org %s: %v", name, err)
+		}
+	}
+	if _, ok := c.Orgs[c.DefaultOrg]; !ok {
+		return fmt.Errorf("default org %q is not one of the configured orgs", c.DefaultOrg)
+	}
 	return nil
 }
 
-// Print outputs the configuration for debugging
+// AuthFlowName returns the auth flow to use for the org. When SALESFORCE_AUTH_FLOW is not set, a
+// Salesforce CLI org alias and then a refresh token saved by `soql-mcp login` take
+// precedence over the password grant.
+func (c *OrgConfig) AuthFlowName() string {
+	if c.SalesforceAuthFlow != "" {
+		return c.SalesforceAuthFlow
+	}
+	if c.SalesforceOrgAlias != "" {
+		return AuthFlowSfdx
+	}
+	if token, err := LoadStoredToken(c.SalesforceTokenFile, c.Name); err == nil && token != nil {
+		return AuthFlowWebServer
+	}
+	return AuthFlowPassword
+}
+
+// Print logs the configuration for debugging. Secrets are never logged.
 func (c *Config) Print() {
-	fmt.Printf("Configuration:\n")
-	fmt.Printf("  Server Name: %s\n", c.ServerName)
-	fmt.Printf("  Server Version: %s\n", c.ServerVersion)
-	fmt.Printf("  Commit: %s\n", c.Commit)
-	fmt.Printf("  Resource Path: %s\n", c.ResourcePath)
-	fmt.Printf("  Debug: %t\n", c.Debug)
-	fmt.Printf("  Log Level: %s\n", c.LogLevel)
-	fmt.Printf("  Salesforce URL: %s\n", c.SalesforceURL)
-	fmt.Printf("  Salesforce Client ID: %s\n", c.SalesforceClientID)
-	fmt.Printf("  Salesforce Username: %s\n", c.SalesforceUsername)
+	log := Logger()
+	log.Info("configuration",
+		"server_name", c.ServerName,
+		"server_version", c.ServerVersion,
+		"commit", c.Commit,
+		"config_file", c.ConfigFile,
+		"resource_path", c.ResourcePath,
+		"debug", c.Debug,
+		"log_level", c.LogLevel,
+		"log_format", c.LogFormat,
+		"log_file", c.LogFile,
+		"transport", c.Transport,
+		"listen", c.ListenAddr,
+		"describe_cache_dir", c.DescribeCacheDir,
+		"allow_writes", c.AllowWrites,
+		"bulk_dir", c.BulkDir,
+		"default_org", c.DefaultOrg,
+	)
+	for _, name := range c.OrgNames() {
+		org := c.Orgs[name]
+		log.Info("org configuration",
+			"org", name,
+			"url", org.SalesforceURL,
+			"client_id", org.SalesforceClientID,
+			"username", org.SalesforceUsername,
+			"auth_flow", org.AuthFlowName(),
+			"org_alias", org.SalesforceOrgAlias,
+			"api_version", org.SalesforceAPIVersion,
+			"query_max_limit", org.QueryPolicy.MaxLimit,
+			"query_deny_objects", strings.Join(org.QueryPolicy.DenyObjects, ","),
+			"query_deny_fields", strings.Join(org.QueryPolicy.DenyFields, ","),
+			"query_denied_fields", org.QueryPolicy.DeniedFields,
+		)
+	}
 }
 
 // GetEnvWithDefault returns the value of an environment variable or a default value if not set
@@ -94,13 +414,3 @@ func GetEnvWithDefault(key, defaultValue string) string {
 	}
 	return defaultValue
 }
-
-// getEnvBool returns a boolean environment variable value
-func getEnvBool(key string, defaultValue bool) bool {
-	if value := os.Getenv(key); value != "" {
-		if parsed, err := strconv.ParseBool(value); err == nil {
-			return parsed
-		}
-	}
-	return defaultValue
-}
@@ -0,0 +1,161 @@
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fileConfig is the YAML config file layout. Every setting mirrors an environment variable.
+type fileConfig struct {
+	ServerName       string                   `yaml:"server_name"`
+	ServerVersion    string                   `yaml:"server_version"`
+	ResourcePath     string                   `yaml:"resource_path"`
+	Debug            *bool                    `yaml:"debug"`
+	LogLevel         string                   `yaml:"log_level"`
+	LogFormat        string                   `yaml:"log_format"`
+	LogFile          string                   `yaml:"log_file"`
+	Transport        string                   `yaml:"transport"`
+	Listen           string                   `yaml:"listen"`
+	AuthToken        string                   `yaml:"auth_token"`
+	AuthTokenFile    string                   `yaml:"auth_token_file"`
+	DescribeCacheDir string                   `yaml:"describe_cache_dir"`
+	AllowWrites      *bool                    `yaml:"allow_writes"`
+	BulkDir          string                   `yaml:"bulk_dir"`
+	DefaultOrg       string                   `yaml:"default_org"`
+	Salesforce       fileOrgConfig            `yaml:"salesforce"`
+	Orgs             map[string]fileOrgConfig `yaml:"orgs"`
+}
+
+// fileOrgConfig holds the Salesforce settings shared by every org or set for one profile
+type fileOrgConfig struct {
+	URL            string `yaml:"url"`
+	ClientID       string `yaml:"client_id"`
+	ClientSecret   string `yaml:"client_secret"`
+	Username       string `yaml:"username"`
+	Password       string `yaml:"password"`
+	SecurityToken  string `yaml:"security_token"`
+	AuthFlow       string `yaml:"auth_flow"`
+	PrivateKeyPath string `yaml:"private_key_path"`
+	JWTAudience    string `yaml:"jwt_audience"`
+	RedirectURI    string `yaml:"redirect_uri"`
+	TokenFile      string `yaml:"token_file"`
+	OrgAlias       string `yaml:"org_alias"`
+	SessionTimeout string `yaml:"session_timeout"`
+	Timeout        string `yaml:"timeout"`
+	APIVersion     string `yaml:"api_version"`
+	// QueryPolicy restricts the SOQL queries run against the org
+	QueryPolicy fileQueryPolicy `yaml:"query_policy"`
+}
+
+// fileQueryPolicy is the query_policy section of an org
+type fileQueryPolicy struct {
+	MaxLimit     int      `yaml:"max_limit"`
+	DenyObjects  []string `yaml:"deny_objects"`
+	DenyFields   []string `yaml:"deny_fields"`
+	DeniedFields string   `yaml:"denied_fields"`
+}
+
+// DefaultConfigFile returns the config file looked up when no path is given,
+// $XDG_CONFIG_HOME/soql-mcp/config.yaml or ~/.config/soql-mcp/config.yaml
+func DefaultConfigFile() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "soql-mcp", "config.yaml")
+}
+
+// readConfigFile loads a YAML config file as settings keyed by environment variable name.
+// A missing file is only an error when required is set.
+func readConfigFile(path string, required bool) (map[string]string, error) {
+	values := map[string]string{}
+	if path == "" {
+		return values, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) && !required {
+		return values, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %v", err)
+	}
+
+	var file fileConfig
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %v", path, err)
+	}
+
+	set := func(key, value string) {
+		if value != "" {
+			values[key] = value
+		}
+	}
+	setBool := func(key string, value *bool) {
+		if value != nil {
+			values[key] = strconv.FormatBool(*value)
+		}
+	}
+
+	set("MCP_SERVER_NAME", file.ServerName)
+	set("MCP_SERVER_VERSION", file.ServerVersion)
+	set("MCP_RESOURCE_PATH", file.ResourcePath)
+	setBool("MCP_DEBUG", file.Debug)
+	set("MCP_LOG_LEVEL", file.LogLevel)
+	set("MCP_LOG_FORMAT", file.LogFormat)
+	set("MCP_LOG_FILE", file.LogFile)
+	set("MCP_TRANSPORT", file.Transport)
+	set("MCP_LISTEN", file.Listen)
+	set("MCP_AUTH_TOKEN", file.AuthToken)
+	set("MCP_AUTH_TOKEN_FILE", file.AuthTokenFile)
+	set("SALESFORCE_DESCRIBE_CACHE_DIR", file.DescribeCacheDir)
+	setBool("SALESFORCE_ALLOW_WRITES", file.AllowWrites)
+	set("SALESFORCE_BULK_DIR", file.BulkDir)
+	set("SALESFORCE_DEFAULT_ORG", file.DefaultOrg)
+
+	file.Salesforce.settings("SALESFORCE_", set)
+	names := make([]string, 0, len(file.Orgs))
+	for name, org := range file.Orgs {
+		names = append(names, name)
+		org.settings("SALESFORCE_"+envName(name)+"_", set)
+	}
+	if len(names) > 0 {
+		values["SALESFORCE_ORGS"] = joinSorted(names)
+	}
+
+	return values, nil
+}
+
+// settings maps the org settings to environment variable names with the given prefix
+func (org fileOrgConfig) settings(prefix string, set func(key, value string)) {
+	set(prefix+"URL", org.URL)
+	set(prefix+"CLIENT_ID", org.ClientID)
+	set(prefix+"CLIENT_SECRET", org.ClientSecret)
+	set(prefix+"USERNAME", org.Username)
+	set(prefix+"PASSWORD", org.Password)
+	set(prefix+"SECURITY_TOKEN", org.SecurityToken)
+	set(prefix+"AUTH_FLOW", org.AuthFlow)
+	set(prefix+"PRIVATE_KEY_PATH", org.PrivateKeyPath)
+	set(prefix+"JWT_AUDIENCE", org.JWTAudience)
+	set(prefix+"REDIRECT_URI", org.RedirectURI)
+	set(prefix+"TOKEN_FILE", org.TokenFile)
+	set(prefix+"ORG_ALIAS", org.OrgAlias)
+	set(prefix+"SESSION_TIMEOUT", org.SessionTimeout)
+	set(prefix+"TIMEOUT", org.Timeout)
+	set(prefix+"API_VERSION", org.APIVersion)
+	if org.QueryPolicy.MaxLimit != 0 {
+		set(prefix+"QUERY_MAX_LIMIT", strconv.Itoa(org.QueryPolicy.MaxLimit))
+	}
+	set(prefix+"QUERY_DENY_OBJECTS", strings.Join(org.QueryPolicy.DenyObjects, ","))
+	set(prefix+"QUERY_DENY_FIELDS", strings.Join(org.QueryPolicy.DenyFields, ","))
+	set(prefix+"QUERY_DENIED_FIELDS", org.QueryPolicy.DeniedFields)
+}
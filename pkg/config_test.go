@@ -0,0 +1,74 @@
+package pkg
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoadOrgConfigProfileKeys(t *testing.T) {
+	s := &settings{file: map[string]string{
+		"SALESFORCE_URL":                     "https://login.salesforce.com",
+		"SALESFORCE_USERNAME":                "shared@example.com",
+		"SALESFORCE_DEFAULT_USERNAME":        "default@example.com",
+		"SALESFORCE_UAT_USERNAME":            "uat@example.com",
+		"SALESFORCE_UAT_URL":                 "https://test.salesforce.com",
+		"SALESFORCE_DEFAULT_QUERY_MAX_LIMIT": "500",
+	}}
+
+	tests := []struct {
+		name     string
+		username string
+		url      string
+		maxLimit int
+	}{
+		{name: DefaultOrgName, username: "default@example.com", url: "https://login.salesforce.com", maxLimit: 500},
+		{name: "uat", username: "uat@example.com", url: "https://test.salesforce.com"},
+		{name: "prod", username: "shared@example.com", url: "https://login.salesforce.com"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			org, err := loadOrgConfig(s, test.name)
+			if err != nil {
+				t.Fatalf("loadOrgConfig failed: %v", err)
+			}
+			if org.SalesforceUsername != test.username || org.SalesforceURL != test.url {
+				t.Errorf("username, url = %q, %q, want %q, %q", org.SalesforceUsername, org.SalesforceURL, test.username, test.url)
+			}
+			if org.QueryPolicy.MaxLimit != test.maxLimit {
+				t.Errorf("QueryPolicy.MaxLimit = %d, want %d", org.QueryPolicy.MaxLimit, test.maxLimit)
+			}
+		})
+	}
+}
+
+func TestLoadOrgConfigSourcePrecedence(t *testing.T) {
+	s := &settings{
+		flags: map[string]string{"SALESFORCE_URL": "https://flag.my.salesforce.com"},
+		file: map[string]string{
+			"SALESFORCE_UAT_USERNAME": "file-uat@example.com",
+			"SALESFORCE_UAT_URL":      "https://file.my.salesforce.com",
+			"SALESFORCE_UAT_TIMEOUT":  "30s",
+		},
+	}
+	t.Setenv("SALESFORCE_USERNAME", "env@example.com")
+	t.Setenv("SALESFORCE_TIMEOUT", "10s")
+	t.Setenv("SALESFORCE_UAT_TIMEOUT", "20s")
+
+	org, err := loadOrgConfig(s, "uat")
+	if err != nil {
+		t.Fatalf("loadOrgConfig failed: %v", err)
+	}
+	// The environment outranks the file, even for the shared key
+	if org.SalesforceUsername != "env@example.com" {
+		t.Errorf("SalesforceUsername = %q, want the environment's shared username", org.SalesforceUsername)
+	}
+	// Flags outrank both
+	if org.SalesforceURL != "https://flag.my.salesforce.com" {
+		t.Errorf("SalesforceURL = %q, want the flag's URL", org.SalesforceURL)
+	}
+	// Within one source the profile key wins
+	if org.SalesforceTimeout != 20*time.Second {
+		t.Errorf("SalesforceTimeout = %s, want the environment's profile timeout", org.SalesforceTimeout)
+	}
+}
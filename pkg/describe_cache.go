@@ -0,0 +1,119 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// DescribeCache stores describe results per org, API version and object in memory and,
+// when a directory is configured, on disk. Entries are revalidated with If-Modified-Since.
+type DescribeCache struct {
+	dir     string
+	entries map[string]*describeCacheEntry
+	stats   DescribeCacheStats
+	mutex   sync.Mutex
+}
+
+// describeCacheEntry is a cached describe response body and its modification date
+type describeCacheEntry struct {
+	LastModified string          `json:"lastModified"`
+	Body         json.RawMessage `json:"body"`
+}
+
+// DescribeCacheStats counts how describe requests were served
+type DescribeCacheStats struct {
+	Entries   int    `json:"entries"`
+	Hits      int    `json:"hits"`
+	Misses    int    `json:"misses"`
+	Changed   int    `json:"changed"`
+	Refreshes int    `json:"refreshes"`
+	Dir       string `json:"dir,omitempty"`
+}
+
+// NewDescribeCache creates a describe cache, persisting entries under dir when it is not empty
+func NewDescribeCache(dir string) *DescribeCache {
+	return &DescribeCache{
+		dir:     dir,
+		entries: map[string]*describeCacheEntry{},
+	}
+}
+
+// describeCacheKey identifies a cached describe
+func describeCacheKey(org, apiVersion, objectType string) string {
+	return strings.Join([]string{org, apiVersion, strings.ToLower(objectType)}, "/")
+}
+
+// get returns the cached entry for key, loading it from disk when it is not in memory
+func (c *DescribeCache) get(key string) *describeCacheEntry {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if entry, ok := c.entries[key]; ok {
+		return entry
+	}
+	if c.dir == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil
+	}
+	var entry describeCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil
+	}
+	c.entries[key] = &entry
+	return &entry
+}
+
+// put stores an entry in memory and on disk
+func (c *DescribeCache) put(key string, entry *describeCacheEntry) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.entries[key] = entry
+	if c.dir == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode describe cache entry: %v", err)
+	}
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create describe cache directory: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write describe cache entry: %v", err)
+	}
+	return nil
+}
+
+// path returns the file an entry is persisted to
+func (c *DescribeCache) path(key string) string {
+	return filepath.Join(c.dir, filepath.FromSlash(key)+".json")
+}
+
+// record updates the statistics with one describe outcome
+func (c *DescribeCache) record(update func(stats *DescribeCacheStats)) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	update(&c.stats)
+}
+
+// Stats returns a snapshot of the cache statistics
+func (c *DescribeCache) Stats() DescribeCacheStats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	stats := c.stats
+	stats.Entries = len(c.entries)
+	stats.Dir = c.dir
+	return stats
+}
@@ -0,0 +1,91 @@
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDescribeCacheRevalidation(t *testing.T) {
+	lastModified, label := "Mon, 05 Oct 2026 10:00:00 GMT", "Account"
+	var ifModifiedSince []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/services/data/v62.0/sobjects/Account/describe" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		since := r.Header.Get("If-Modified-Since")
+		ifModifiedSince = append(ifModifiedSince, since)
+		if since == lastModified {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Last-Modified", lastModified)
+		json.NewEncoder(w).Encode(SalesforceDescribeResponse{Name: "Account", Label: label})
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	newClient := func(cache *DescribeCache) *SalesforceClient {
+		sf := NewSalesforceClient(&OrgConfig{Name: "test"})
+		sf.auth = &SalesforceAuth{AccessToken: "token", InstanceURL: server.URL}
+		sf.version = "v62.0"
+		sf.describeCache = cache
+		return sf
+	}
+	cache := NewDescribeCache(dir)
+	sf := newClient(cache)
+
+	steps := []struct {
+		name    string
+		change  string
+		refresh bool
+		client  *SalesforceClient
+		since   string
+		label   string
+		stats   DescribeCacheStats
+	}{
+		{name: "miss", since: "", label: "Account",
+			stats: DescribeCacheStats{Entries: 1, Misses: 1}},
+		{name: "not modified", since: lastModified, label: "Account",
+			stats: DescribeCacheStats{Entries: 1, Misses: 1, Hits: 1}},
+		{name: "changed", change: "Customer", since: lastModified, label: "Customer",
+			stats: DescribeCacheStats{Entries: 1, Misses: 1, Hits: 1, Changed: 1}},
+		{name: "refresh", refresh: true, since: "", label: "Customer",
+			stats: DescribeCacheStats{Entries: 1, Misses: 1, Hits: 1, Changed: 1, Refreshes: 1}},
+		{name: "not modified after change", since: "Tue, 06 Oct 2026 10:00:00 GMT", label: "Customer",
+			stats: DescribeCacheStats{Entries: 1, Misses: 1, Hits: 2, Changed: 1, Refreshes: 1}},
+		{name: "loaded from disk", client: newClient(NewDescribeCache(dir)), since: "Tue, 06 Oct 2026 10:00:00 GMT", label: "Customer",
+			stats: DescribeCacheStats{Entries: 1, Hits: 1}},
+	}
+
+	for _, step := range steps {
+		if step.change != "" {
+			lastModified, label = "Tue, 06 Oct 2026 10:00:00 GMT", step.change
+		}
+		client := sf
+		if step.client != nil {
+			client = step.client
+		}
+		ifModifiedSince = nil
+
+		result, err := client.Describe(context.Background(), "Account", step.refresh)
+		if err != nil {
+			t.Fatalf("%s: Describe failed: %v", step.name, err)
+		}
+		if len(ifModifiedSince) != 1 || ifModifiedSince[0] != step.since {
+			t.Errorf("%s: If-Modified-Since = %q, want %q", step.name, ifModifiedSince, step.since)
+		}
+		if result.Label != step.label {
+			t.Errorf("%s: Label = %q, want %q", step.name, result.Label, step.label)
+		}
+		stats := client.describeCache.Stats()
+		stats.Dir = ""
+		if stats != step.stats {
+			t.Errorf("%s: stats = %+v, want %+v", step.name, stats, step.stats)
+		}
+	}
+}
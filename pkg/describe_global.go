@@ -0,0 +1,105 @@
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// SalesforceSObjectSummary represents one sObject in the describeGlobal response
+type SalesforceSObjectSummary struct {
+	Name        string `json:"name"`
+	Label       string `json:"label"`
+	LabelPlural string `json:"labelPlural"`
+	KeyPrefix   string `json:"keyPrefix"`
+	Custom      bool   `json:"custom"`
+	Queryable   bool   `json:"queryable"`
+	Createable  bool   `json:"createable"`
+	Updateable  bool   `json:"updateable"`
+	Deletable   bool   `json:"deletable"`
+	Searchable  bool   `json:"searchable"`
+}
+
+// SalesforceDescribeGlobalResponse represents the response from the describeGlobal API
+type SalesforceDescribeGlobalResponse struct {
+	Encoding     string                     `json:"encoding"`
+	MaxBatchSize int                        `json:"maxBatchSize"`
+	SObjects     []SalesforceSObjectSummary `json:"sobjects"`
+}
+
+// SObjectFilter selects sObjects from a describeGlobal response
+type SObjectFilter struct {
+	CustomOnly    bool
+	QueryableOnly bool
+	Search        string
+}
+
+// DescribeGlobal lists every sObject available in the org
+func (sf *SalesforceClient) DescribeGlobal(ctx context.Context) (*SalesforceDescribeGlobalResponse, error) {
+	// Prepare describeGlobal URL
+	describeGlobalURL, err := sf.dataURL("sobjects")
+	if err != nil {
+		return nil, err
+	}
+
+	var result SalesforceDescribeGlobalResponse
+	if err := sf.getJSON(ctx, describeGlobalURL, "describeGlobal", &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// Filter returns the sObjects matching the filter. Search matches the API name or
+// label as a case-insensitive substring.
+func (result *SalesforceDescribeGlobalResponse) Filter(filter SObjectFilter) []SalesforceSObjectSummary {
+	search := strings.ToLower(filter.Search)
+
+	var sobjects []SalesforceSObjectSummary
+	for _, sobject := range result.SObjects {
+		if filter.CustomOnly && !sobject.Custom {
+			continue
+		}
+		if filter.QueryableOnly && !sobject.Queryable {
+			continue
+		}
+		if search != "" && !strings.Contains(strings.ToLower(sobject.Name), search) &&
+			!strings.Contains(strings.ToLower(sobject.Label), search) {
+			continue
+		}
+		sobjects = append(sobjects, sobject)
+	}
+	return sobjects
+}
+
+// FormatSObjectsAsTable formats an sObject list as a readable table
+func FormatSObjectsAsTable(sobjects []SalesforceSObjectSummary) string {
+	if len(sobjects) == 0 {
+		return "No objects found."
+	}
+
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("Objects: %d\n", len(sobjects)))
+	buffer.WriteString(fmt.Sprintf("%-40s %-30s %-7s %-7s %-10s %-10s %-10s\n",
+		"Name", "Label", "Prefix", "Custom", "Queryable", "Createable", "Searchable"))
+	buffer.WriteString(strings.Repeat("-", 120) + "\n")
+
+	for _, sobject := range sobjects {
+		buffer.WriteString(fmt.Sprintf("%-40s %-30s %-7s %-7t %-10t %-10t %-10t\n",
+			sobject.Name, sobject.Label, sobject.KeyPrefix, sobject.Custom,
+			sobject.Queryable, sobject.Createable, sobject.Searchable))
+	}
+
+	return buffer.String()
+}
+
+// FormatSObjectsAsJSON formats an sObject list as JSON
+func FormatSObjectsAsJSON(sobjects []SalesforceSObjectSummary) string {
+	if sobjects == nil {
+		sobjects = []SalesforceSObjectSummary{}
+	}
+	jsonBytes, _ := json.MarshalIndent(sobjects, "", "  ")
+	return string(jsonBytes)
+}
@@ -0,0 +1,111 @@
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// SalesforceExplainNote represents an optimizer note attached to a query plan
+type SalesforceExplainNote struct {
+	Description   string   `json:"description"`
+	Fields        []string `json:"fields"`
+	TableEnumOrID string   `json:"tableEnumOrId"`
+}
+
+// SalesforceExplainPlan represents one candidate plan from the query optimizer
+type SalesforceExplainPlan struct {
+	LeadingOperationType string                  `json:"leadingOperationType"`
+	Cardinality          int64                   `json:"cardinality"`
+	Fields               []string                `json:"fields"`
+	RelativeCost         float64                 `json:"relativeCost"`
+	SobjectCardinality   int64                   `json:"sobjectCardinality"`
+	SobjectType          string                  `json:"sobjectType"`
+	Notes                []SalesforceExplainNote `json:"notes"`
+}
+
+// SalesforceExplainResponse represents the response from the query explain API
+type SalesforceExplainResponse struct {
+	Plans []SalesforceExplainPlan `json:"plans"`
+	// PolicyNotes describes how the org's query policy rewrote the query
+	PolicyNotes []string `json:"policyNotes,omitempty"`
+}
+
+// Explain returns the query optimizer's plans for a SOQL query without running it. The
+// query is checked against the org's deny lists first.
+func (sf *SalesforceClient) Explain(ctx context.Context, query string) (*SalesforceExplainResponse, error) {
+	query, policyNotes, err := sf.applyExplainPolicy(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	// URL encode the query
+	params := url.Values{}
+	params.Add("explain", query)
+	explainURL, err := sf.dataURL("query?" + params.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	var result SalesforceExplainResponse
+	if err := sf.getJSON(ctx, explainURL, "explain", &result); err != nil {
+		return nil, err
+	}
+	result.PolicyNotes = policyNotes
+
+	return &result, nil
+}
+
+// AllTableScans reports whether every plan is a full table scan
+func (result *SalesforceExplainResponse) AllTableScans() bool {
+	if len(result.Plans) == 0 {
+		return false
+	}
+	for _, plan := range result.Plans {
+		if plan.LeadingOperationType != "TableScan" {
+			return false
+		}
+	}
+	return true
+}
+
+// FormatExplainAsTable formats query plans as a readable table
+func FormatExplainAsTable(result *SalesforceExplainResponse) string {
+	var notes bytes.Buffer
+	for _, note := range result.PolicyNotes {
+		notes.WriteString(fmt.Sprintf("Query policy: %s\n", note))
+	}
+	if len(result.Plans) == 0 {
+		return notes.String() + "No query plans returned."
+	}
+
+	var buffer bytes.Buffer
+	buffer.WriteString(notes.String())
+	buffer.WriteString(fmt.Sprintf("%-4s %-20s %-20s %-12s %-14s %-14s %s\n",
+		"#", "Operation", "sObject", "Cost", "Cardinality", "sObject Rows", "Fields"))
+	buffer.WriteString(strings.Repeat("-", 100) + "\n")
+
+	for i, plan := range result.Plans {
+		buffer.WriteString(fmt.Sprintf("%-4d %-20s %-20s %-12.4f %-14d %-14d %s\n",
+			i+1, plan.LeadingOperationType, plan.SobjectType, plan.RelativeCost,
+			plan.Cardinality, plan.SobjectCardinality, strings.Join(plan.Fields, ", ")))
+		for _, note := range plan.Notes {
+			buffer.WriteString(fmt.Sprintf("     Note: %s", note.Description))
+			if len(note.Fields) > 0 {
+				buffer.WriteString(fmt.Sprintf(" (%s: %s)", note.TableEnumOrID, strings.Join(note.Fields, ", ")))
+			}
+			buffer.WriteString("\n")
+		}
+	}
+
+	return buffer.String()
+}
+
+// FormatExplainAsJSON formats query plans as JSON
+func FormatExplainAsJSON(result *SalesforceExplainResponse) string {
+	jsonBytes, _ := json.MarshalIndent(result, "", "  ")
+	return string(jsonBytes)
+}
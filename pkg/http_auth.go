@@ -0,0 +1,85 @@
+package pkg
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// BearerTokenAuth protects the network transports with a static bearer token and/or a
+// token file holding one accepted token per line. The file is re-read on every request
+// so tokens can be rotated without a restart.
+type BearerTokenAuth struct {
+	Token     string
+	TokenFile string
+}
+
+// Validate checks that at least one token source is configured and readable
+func (a *BearerTokenAuth) Validate() error {
+	if a.Token == "" && a.TokenFile == "" {
+		return fmt.Errorf("network transports require MCP_AUTH_TOKEN or MCP_AUTH_TOKEN_FILE")
+	}
+	if a.TokenFile != "" {
+		if _, err := a.fileTokens(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fileTokens reads the accepted tokens from the token file
+func (a *BearerTokenAuth) fileTokens() ([]string, error) {
+	data, err := os.ReadFile(a.TokenFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read auth token file: %v", err)
+	}
+
+	var tokens []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			tokens = append(tokens, line)
+		}
+	}
+	return tokens, nil
+}
+
+// authorized reports whether token matches one of the accepted tokens
+func (a *BearerTokenAuth) authorized(token string) bool {
+	if token == "" {
+		return false
+	}
+
+	accepted := []string{}
+	if a.Token != "" {
+		accepted = append(accepted, a.Token)
+	}
+	if a.TokenFile != "" {
+		if tokens, err := a.fileTokens(); err == nil {
+			accepted = append(accepted, tokens...)
+		}
+	}
+
+	for _, candidate := range accepted {
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(token)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// Middleware rejects requests without a valid Authorization: Bearer header
+func (a *BearerTokenAuth) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		token, found := strings.CutPrefix(header, "Bearer ")
+		if !found || !a.authorized(strings.TrimSpace(token)) {
+			Logger().Warn("rejected unauthorized request", "remote_addr", r.RemoteAddr, "path", r.URL.Path)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="soql-mcp"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
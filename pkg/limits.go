@@ -0,0 +1,88 @@
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SalesforceLimit represents the maximum and remaining allocation of one org limit
+type SalesforceLimit struct {
+	Max       int64 `json:"Max"`
+	Remaining int64 `json:"Remaining"`
+}
+
+// SalesforceLimitsResponse represents the response from the limits API, keyed by limit name
+type SalesforceLimitsResponse map[string]SalesforceLimit
+
+// Limits gets the org's current limits and remaining allocations
+func (sf *SalesforceClient) Limits(ctx context.Context) (SalesforceLimitsResponse, error) {
+	// Prepare limits URL
+	limitsURL, err := sf.dataURL("limits")
+	if err != nil {
+		return nil, err
+	}
+
+	var result SalesforceLimitsResponse
+	if err := sf.getJSON(ctx, limitsURL, "limits", &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Filter returns the limits whose names contain any of the given names, ignoring case.
+// With no names every limit is returned.
+func (limits SalesforceLimitsResponse) Filter(names []string) SalesforceLimitsResponse {
+	if len(names) == 0 {
+		return limits
+	}
+
+	filtered := SalesforceLimitsResponse{}
+	for name, limit := range limits {
+		for _, want := range names {
+			if strings.Contains(strings.ToLower(name), strings.ToLower(want)) {
+				filtered[name] = limit
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// FormatLimitsAsTable formats org limits as a readable table
+func FormatLimitsAsTable(limits SalesforceLimitsResponse) string {
+	if len(limits) == 0 {
+		return "No limits found."
+	}
+
+	names := make([]string, 0, len(limits))
+	for name := range limits {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("%-50s %-15s %-15s %-8s\n", "Limit", "Max", "Remaining", "Used"))
+	buffer.WriteString(strings.Repeat("-", 90) + "\n")
+
+	for _, name := range names {
+		limit := limits[name]
+		used := ""
+		if limit.Max > 0 {
+			used = fmt.Sprintf("%.1f%%", float64(limit.Max-limit.Remaining)*100/float64(limit.Max))
+		}
+		buffer.WriteString(fmt.Sprintf("%-50s %-15d %-15d %-8s\n", name, limit.Max, limit.Remaining, used))
+	}
+
+	return buffer.String()
+}
+
+// FormatLimitsAsJSON formats org limits as JSON
+func FormatLimitsAsJSON(limits SalesforceLimitsResponse) string {
+	jsonBytes, _ := json.MarshalIndent(limits, "", "  ")
+	return string(jsonBytes)
+}
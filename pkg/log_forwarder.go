@@ -0,0 +1,186 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// mcpLoggerName is the logger name sent with MCP log notifications
+const mcpLoggerName = "soql-mcp"
+
+// mcpLevelOrder ranks MCP logging levels from least to most severe
+var mcpLevelOrder = map[mcp.LoggingLevel]int{
+	mcp.LoggingLevelDebug:     0,
+	mcp.LoggingLevelInfo:      1,
+	mcp.LoggingLevelNotice:    2,
+	mcp.LoggingLevelWarning:   3,
+	mcp.LoggingLevelError:     4,
+	mcp.LoggingLevelCritical:  5,
+	mcp.LoggingLevelAlert:     6,
+	mcp.LoggingLevelEmergency: 7,
+}
+
+// mcpLevel converts a slog level to an MCP logging level
+func mcpLevel(level slog.Level) mcp.LoggingLevel {
+	switch {
+	case level >= slog.LevelError:
+		return mcp.LoggingLevelError
+	case level >= slog.LevelWarn:
+		return mcp.LoggingLevelWarning
+	case level >= slog.LevelInfo:
+		return mcp.LoggingLevelInfo
+	}
+	return mcp.LoggingLevelDebug
+}
+
+// wantsLevel reports whether a session's logging/setLevel threshold admits level
+func wantsLevel(session server.SessionWithLogging, level slog.Level) bool {
+	return mcpLevelOrder[mcpLevel(level)] >= mcpLevelOrder[session.GetLogLevel()]
+}
+
+// LogForwarder is a slog handler that writes records to the next handler and also sends them
+// to MCP clients as notifications/message. Records logged with a client session in their
+// context go to that session. Other records go to the single client of the stdio transport;
+// on the network transports, where sessions belong to different clients, they only go to the
+// next handler. Each session only receives records at or above the level it asked for with
+// logging/setLevel.
+type LogForwarder struct {
+	next     slog.Handler
+	sessions *sync.Map // session ID -> server.SessionWithLogging
+	// broadcast sends records without a session to every session
+	broadcast bool
+	attrs     []slog.Attr
+	prefix    string
+}
+
+// NewLogForwarder wraps next and tracks MCP sessions through the server hooks. Records
+// without a session are only sent to clients on the stdio transport.
+func NewLogForwarder(next slog.Handler, hooks *server.Hooks, transport string) *LogForwarder {
+	f := &LogForwarder{next: next, sessions: &sync.Map{}, broadcast: transport == TransportStdio}
+	hooks.AddOnRegisterSession(func(ctx context.Context, session server.ClientSession) {
+		if logging, ok := session.(server.SessionWithLogging); ok {
+			f.sessions.Store(session.SessionID(), logging)
+		}
+	})
+	hooks.AddOnUnregisterSession(func(ctx context.Context, session server.ClientSession) {
+		f.sessions.Delete(session.SessionID())
+	})
+	return f
+}
+
+// targets returns the sessions a record at level should be sent to
+func (f *LogForwarder) targets(ctx context.Context, level slog.Level) []server.SessionWithLogging {
+	if session, ok := server.ClientSessionFromContext(ctx).(server.SessionWithLogging); ok {
+		if session.Initialized() && wantsLevel(session, level) {
+			return []server.SessionWithLogging{session}
+		}
+		return nil
+	}
+	if !f.broadcast {
+		return nil
+	}
+
+	var sessions []server.SessionWithLogging
+	f.sessions.Range(func(_, value any) bool {
+		session := value.(server.SessionWithLogging)
+		if session.Initialized() && wantsLevel(session, level) {
+			sessions = append(sessions, session)
+		}
+		return true
+	})
+	return sessions
+}
+
+// Enabled implements slog.Handler
+func (f *LogForwarder) Enabled(ctx context.Context, level slog.Level) bool {
+	return f.next.Enabled(ctx, level) || len(f.targets(ctx, level)) > 0
+}
+
+// Handle implements slog.Handler
+func (f *LogForwarder) Handle(ctx context.Context, record slog.Record) error {
+	var err error
+	if f.next.Enabled(ctx, record.Level) {
+		err = f.next.Handle(ctx, record)
+	}
+
+	sessions := f.targets(ctx, record.Level)
+	if len(sessions) == 0 {
+		return err
+	}
+
+	data := map[string]any{"message": record.Message}
+	for _, attr := range f.attrs {
+		data[attr.Key] = notificationValue(attr.Value)
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		data[f.prefix+attr.Key] = notificationValue(attr.Value)
+		return true
+	})
+
+	notification := mcp.JSONRPCNotification{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		Notification: mcp.Notification{
+			Method: "notifications/message",
+			Params: mcp.NotificationParams{
+				AdditionalFields: map[string]any{
+					"level":  mcpLevel(record.Level),
+					"logger": mcpLoggerName,
+					"data":   data,
+				},
+			},
+		},
+	}
+	for _, session := range sessions {
+		// Drop the notification rather than block logging on a slow client
+		select {
+		case session.NotificationChannel() <- notification:
+		default:
+		}
+	}
+	return err
+}
+
+// notificationValue converts a log attribute value to a JSON friendly value
+func notificationValue(value slog.Value) any {
+	value = value.Resolve()
+	switch v := value.Any().(type) {
+	case time.Duration:
+		return v.String()
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return v
+	}
+}
+
+// WithAttrs implements slog.Handler
+func (f *LogForwarder) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *f
+	clone.next = f.next.WithAttrs(attrs)
+	clone.attrs = append([]slog.Attr{}, f.attrs...)
+	for _, attr := range attrs {
+		attr.Key = f.prefix + attr.Key
+		clone.attrs = append(clone.attrs, attr)
+	}
+	return &clone
+}
+
+// WithGroup implements slog.Handler
+func (f *LogForwarder) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return f
+	}
+	clone := *f
+	clone.next = f.next.WithGroup(name)
+	clone.prefix = f.prefix + strings.TrimSuffix(name, ".") + "."
+	return &clone
+}
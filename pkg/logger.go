@@ -0,0 +1,92 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// Supported values for MCP_LOG_FORMAT
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+var logger atomic.Pointer[slog.Logger]
+
+func init() {
+	logger.Store(slog.New(slog.NewTextHandler(os.Stderr, nil)))
+}
+
+// Logger returns the process-wide logger. It writes to stderr until SetLogger is called;
+// stdout is reserved for the stdio transport.
+func Logger() *slog.Logger {
+	return logger.Load()
+}
+
+// SetLogger replaces the process-wide logger
+func SetLogger(l *slog.Logger) {
+	logger.Store(l)
+}
+
+// parseLogLevel converts MCP_LOG_LEVEL to a slog level
+func parseLogLevel(value string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return slog.LevelInfo, fmt.Errorf("unsupported log level %q (supported: debug, info, warn, error)", value)
+}
+
+// NewLogger builds a logger from the log settings. It writes to MCP_LOG_FILE when set
+// and to stderr otherwise; the returned closer closes the log file.
+func NewLogger(config *Config) (*slog.Logger, io.Closer, error) {
+	level, err := parseLogLevel(config.LogLevel)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var out io.Writer = os.Stderr
+	var closer io.Closer = io.NopCloser(nil)
+	if config.LogFile != "" {
+		file, err := os.OpenFile(config.LogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open log file: %v", err)
+		}
+		out, closer = file, file
+	}
+
+	options := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	if config.LogFormat == LogFormatJSON {
+		handler = slog.NewJSONHandler(out, options)
+	} else {
+		handler = slog.NewTextHandler(out, options)
+	}
+	return slog.New(handler), closer, nil
+}
+
+type loggerKey struct{}
+
+// WithLogger returns a context carrying a request-scoped logger
+func WithLogger(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// LoggerFromContext returns the request-scoped logger, or the process-wide logger
+func LoggerFromContext(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return l
+	}
+	return Logger()
+}
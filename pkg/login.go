@@ -0,0 +1,198 @@
+package pkg
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// LoginOptions controls the interactive web server login
+type LoginOptions struct {
+	OpenBrowser bool
+	Timeout     time.Duration
+	Out         io.Writer
+}
+
+// loginResult carries the outcome of the OAuth callback
+type loginResult struct {
+	code string
+	err  error
+}
+
+// Login runs the OAuth web server flow with PKCE, catching the authorization code on a
+// loopback listener, and stores the resulting refresh token in the token store
+func Login(ctx context.Context, config *OrgConfig, opts LoginOptions) (*StoredToken, error) {
+	if config.SalesforceClientID == "" {
+		return nil, fmt.Errorf("SALESFORCE_CLIENT_ID is required")
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 5 * time.Minute
+	}
+	if opts.Out == nil {
+		opts.Out = io.Discard
+	}
+
+	redirectURI, err := url.Parse(config.SalesforceRedirectURI)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SALESFORCE_REDIRECT_URI: %v", err)
+	}
+	if redirectURI.Scheme != "http" || redirectURI.Port() == "" || !isLoopbackHost(redirectURI.Hostname()) {
+		return nil, fmt.Errorf("SALESFORCE_REDIRECT_URI must be a loopback http URL with a port, got %s", config.SalesforceRedirectURI)
+	}
+
+	verifier, err := randomURLSafe(32)
+	if err != nil {
+		return nil, err
+	}
+	state, err := randomURLSafe(16)
+	if err != nil {
+		return nil, err
+	}
+	challenge := sha256.Sum256([]byte(verifier))
+
+	// Start the callback listener before sending the user to Salesforce
+	listener, err := net.Listen("tcp", redirectURI.Host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %v", redirectURI.Host, err)
+	}
+
+	results := make(chan loginResult, 1)
+	// A redirect URI without a path, e.g. http://localhost:1717, is served at the root
+	callbackPath := redirectURI.Path
+	if callbackPath == "" {
+		callbackPath = "/"
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(callbackPath, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		var result loginResult
+		switch {
+		case query.Get("error") != "":
+			result.err = fmt.Errorf("authorization failed: %s - %s", query.Get("error"), query.Get("error_description"))
+		case query.Get("state") != state:
+			result.err = fmt.Errorf("authorization failed: state mismatch")
+		case query.Get("code") == "":
+			result.err = fmt.Errorf("authorization failed: no code in callback")
+		default:
+			result.code = query.Get("code")
+		}
+
+		if result.err != nil {
+			http.Error(w, result.err.Error(), http.StatusBadRequest)
+		} else {
+			fmt.Fprintln(w, "Login complete. You can close this window and return to the terminal.")
+		}
+		select {
+		case results <- result:
+		default:
+		}
+	})
+
+	callbackServer := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go callbackServer.Serve(listener)
+	defer callbackServer.Close()
+
+	// Build the authorization URL
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", config.SalesforceClientID)
+	params.Set("redirect_uri", config.SalesforceRedirectURI)
+	params.Set("scope", "api refresh_token")
+	params.Set("state", state)
+	params.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	params.Set("code_challenge_method", "S256")
+	authURL := strings.TrimRight(config.SalesforceURL, "/") + "/services/oauth2/authorize?" + params.Encode()
+
+	fmt.Fprintf(opts.Out, "Open the following URL to log in:\n\n  %s\n\n", authURL)
+	if opts.OpenBrowser {
+		if err := openBrowser(authURL); err != nil {
+			fmt.Fprintf(opts.Out, "Could not open a browser automatically: %v\n", err)
+		}
+	}
+
+	// Wait for the callback
+	var result loginResult
+	select {
+	case result = <-results:
+	case <-time.After(opts.Timeout):
+		return nil, fmt.Errorf("timed out after %s waiting for the login callback", opts.Timeout)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if result.err != nil {
+		return nil, result.err
+	}
+
+	// Exchange the code for tokens
+	data := url.Values{}
+	data.Set("grant_type", "authorization_code")
+	data.Set("code", result.code)
+	data.Set("client_id", config.SalesforceClientID)
+	if config.SalesforceClientSecret != "" {
+		data.Set("client_secret", config.SalesforceClientSecret)
+	}
+	data.Set("redirect_uri", config.SalesforceRedirectURI)
+	data.Set("code_verifier", verifier)
+
+	auth, err := requestToken(tokenURL(config.SalesforceURL), data)
+	if err != nil {
+		return nil, err
+	}
+	if auth.RefreshToken == "" {
+		return nil, fmt.Errorf("no refresh token returned, check that the connected app allows the refresh_token scope")
+	}
+
+	token := &StoredToken{
+		LoginURL:     config.SalesforceURL,
+		InstanceURL:  auth.InstanceURL,
+		ClientID:     config.SalesforceClientID,
+		RefreshToken: auth.RefreshToken,
+		IssuedAt:     time.Now(),
+	}
+	if err := SaveStoredToken(config.SalesforceTokenFile, config.Name, token); err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// isLoopbackHost reports whether the callback listener would only be reachable from this
+// machine: localhost or a loopback IP address
+func isLoopbackHost(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// randomURLSafe returns n random bytes encoded as unpadded base64url
+func randomURLSafe(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate random value: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// openBrowser opens target in the user's default browser
+func openBrowser(target string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", target).Start()
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", target).Start()
+	default:
+		return exec.Command("xdg-open", target).Start()
+	}
+}
@@ -0,0 +1,29 @@
+package pkg
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestLoginRequiresLoopbackRedirect(t *testing.T) {
+	for _, redirectURI := range []string{
+		"https://localhost:1717/OauthRedirect",
+		"http://localhost/OauthRedirect",
+		"http://0.0.0.0:1717/OauthRedirect",
+		"http://192.168.1.10:1717/OauthRedirect",
+		"http://login.example.com:1717/OauthRedirect",
+	} {
+		config := &OrgConfig{SalesforceClientID: "client", SalesforceRedirectURI: redirectURI}
+		_, err := Login(context.Background(), config, LoginOptions{})
+		if err == nil || !strings.Contains(err.Error(), "must be a loopback http URL") {
+			t.Errorf("Login with %s: got %v, want a loopback error", redirectURI, err)
+		}
+	}
+
+	for _, host := range []string{"localhost", "LOCALHOST", "127.0.0.1", "127.0.0.2", "::1"} {
+		if !isLoopbackHost(host) {
+			t.Errorf("isLoopbackHost(%q) = false, want true", host)
+		}
+	}
+}
@@ -0,0 +1,627 @@
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/zhongxiao37/soql-mcp/pkg/soql"
+)
+
+// Supported values for SALESFORCE_QUERY_DENIED_FIELDS, what happens to denied fields in a
+// select list
+const (
+	DeniedFieldsBlock = "block"
+	DeniedFieldsStrip = "strip"
+)
+
+// lockingClause matches the FOR and UPDATE clauses that lock or modify records, for
+// queries the parser cannot read
+var lockingClause = regexp.MustCompile(`(?i)\b(?:FOR\s+(?:UPDATE|VIEW|REFERENCE)|UPDATE\s+(?:TRACKING|VIEWSTAT))\b`)
+
+// restrictedSearchGroups are the SOSL search groups that only match name, email and
+// phone fields, which may be searched when an object has denied fields
+var restrictedSearchGroups = map[string]bool{"NAME": true, "EMAIL": true, "PHONE": true}
+
+// Query policy rules, reported in violations
+const (
+	PolicyRuleParse       = "parse"
+	PolicyRuleMaxLimit    = "max_limit"
+	PolicyRuleDenyObject  = "deny_object"
+	PolicyRuleDenyField   = "deny_field"
+	PolicyRuleReadOnly    = "read_only"
+	PolicyRuleEmptySelect = "empty_select"
+)
+
+// QueryPolicy restricts the SOQL queries run for an org profile. Queries are checked
+// before they reach Salesforce. Queries that lock or modify records are rejected for every
+// profile, including one without any setting.
+type QueryPolicy struct {
+	// MaxLimit is the largest LIMIT allowed; queries without a LIMIT get this one
+	MaxLimit int `json:"maxLimit,omitempty"`
+	// DenyObjects are objects that cannot be queried, directly or through relationships
+	DenyObjects []string `json:"denyObjects,omitempty"`
+	// DenyFields are field names denied on every object, or Object.Field for one object
+	DenyFields []string `json:"denyFields,omitempty"`
+	// DeniedFields is block to reject queries selecting a denied field or strip to
+	// remove the field from the select list
+	DeniedFields string `json:"deniedFields,omitempty"`
+}
+
+// Enabled reports whether the policy sets a maximum LIMIT or deny lists
+func (p QueryPolicy) Enabled() bool {
+	return p.MaxLimit > 0 || len(p.DenyObjects) > 0 || len(p.DenyFields) > 0
+}
+
+// Validate checks the policy settings
+func (p QueryPolicy) Validate() error {
+	if p.MaxLimit < 0 {
+		return fmt.Errorf("query max limit cannot be negative")
+	}
+	switch p.DeniedFields {
+	case DeniedFieldsBlock, DeniedFieldsStrip:
+	default:
+		return fmt.Errorf("unsupported denied fields action %q (supported: block, strip)", p.DeniedFields)
+	}
+	return nil
+}
+
+// String summarizes the policy, e.g. "max LIMIT 2000; denied objects: User"
+func (p QueryPolicy) String() string {
+	var parts []string
+	if p.MaxLimit > 0 {
+		parts = append(parts, fmt.Sprintf("max LIMIT %d", p.MaxLimit))
+	}
+	if len(p.DenyObjects) > 0 {
+		parts = append(parts, "denied objects: "+strings.Join(p.DenyObjects, ", "))
+	}
+	if len(p.DenyFields) > 0 {
+		parts = append(parts, fmt.Sprintf("denied fields (%s): %s", p.DeniedFields, strings.Join(p.DenyFields, ", ")))
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, "; ")
+}
+
+// objectDenied reports whether an object is on the deny list
+func (p QueryPolicy) objectDenied(object string) bool {
+	return containsFold(p.DenyObjects, object)
+}
+
+// fieldDenied reports whether a field of an object is on the deny list
+func (p QueryPolicy) fieldDenied(object, field string) bool {
+	for _, entry := range p.DenyFields {
+		if i := strings.LastIndex(entry, "."); i >= 0 {
+			if strings.EqualFold(entry[:i], object) && strings.EqualFold(entry[i+1:], field) {
+				return true
+			}
+		} else if strings.EqualFold(entry, field) {
+			return true
+		}
+	}
+	return false
+}
+
+// QueryPolicyViolation is one way a query breaks the org's query policy
+type QueryPolicyViolation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+	Token   string `json:"token,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+}
+
+// QueryPolicyError is returned for a query that violates the org's query policy
+type QueryPolicyError struct {
+	Org        string                 `json:"org"`
+	Violations []QueryPolicyViolation `json:"violations"`
+}
+
+func (e *QueryPolicyError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		messages[i] = violation.Message
+	}
+	return fmt.Sprintf("query policy of org %s violated: %s", e.Org, strings.Join(messages, "; "))
+}
+
+// FormatQueryPolicyError formats a policy violation as JSON for tool errors
+func FormatQueryPolicyError(err *QueryPolicyError) string {
+	jsonBytes, _ := json.MarshalIndent(struct {
+		Error string `json:"error"`
+		*QueryPolicyError
+	}{Error: "query_policy_violation", QueryPolicyError: err}, "", "  ")
+	return string(jsonBytes)
+}
+
+// applyQueryPolicy checks a query against the org's query policy. It returns the query to
+// run, which is rewritten when a LIMIT is added or denied fields are stripped, and notes
+// describing each rewrite. Violations are returned as a *QueryPolicyError.
+func (sf *SalesforceClient) applyQueryPolicy(ctx context.Context, query string) (string, []string, error) {
+	return sf.checkQueryPolicy(ctx, query, false)
+}
+
+// applyExplainPolicy checks a query whose plans are explained against the org's deny
+// lists. The query is not run, so its LIMIT and locking clauses are left alone.
+func (sf *SalesforceClient) applyExplainPolicy(ctx context.Context, query string) (string, []string, error) {
+	return sf.checkQueryPolicy(ctx, query, true)
+}
+
+func (sf *SalesforceClient) checkQueryPolicy(ctx context.Context, query string, explain bool) (string, []string, error) {
+	policy := sf.config.QueryPolicy
+	checker := &queryPolicyChecker{
+		describeMemo: newDescribeMemo(sf),
+		policy:       policy,
+		metadata:     len(policy.DenyObjects) > 0 || len(policy.DenyFields) > 0,
+		explain:      explain,
+	}
+
+	parsed, err := soql.Parse(query)
+	if err != nil {
+		// Queries the policy cannot read are not run
+		var parseErr *soql.ParseError
+		if !errors.As(err, &parseErr) {
+			return "", nil, err
+		}
+		switch {
+		case explain && !checker.metadata:
+			return query, nil, nil
+		case explain || policy.Enabled():
+			checker.violation(PolicyRuleParse, parseErr.Pos, "", "query cannot be checked: %s", parseErr.Message)
+		case lockingClause.MatchString(query):
+			// Without limits or deny lists only the read-only rule applies, so an
+			// unreadable query is only rejected when it looks like it locks records
+			checker.violation(PolicyRuleReadOnly, parseErr.Pos, "",
+				"query cannot be checked (%s) and may lock or modify records", parseErr.Message)
+		default:
+			return query, nil, nil
+		}
+	} else if err := checker.query(ctx, parsed, nil); err != nil {
+		return "", nil, fmt.Errorf("query policy check failed: %v", err)
+	}
+
+	if len(checker.violations) > 0 {
+		sf.log().WarnContext(ctx, "query rejected by policy", "violations", len(checker.violations))
+		return "", nil, &QueryPolicyError{Org: sf.config.Name, Violations: checker.violations}
+	}
+	if !checker.rewritten {
+		return query, nil, nil
+	}
+
+	query = parsed.String()
+	sf.log().InfoContext(ctx, "query rewritten by policy", "query", query)
+	return query, checker.notes, nil
+}
+
+// applySearchPolicy checks the RETURNING clause of a SOSL search against the org's deny
+// lists, the way applyQueryPolicy checks a SOQL query. It returns the search to run, which
+// is rewritten when denied fields are stripped, and notes describing each rewrite. The
+// maximum LIMIT only applies to SOQL.
+func (sf *SalesforceClient) applySearchPolicy(ctx context.Context, search string) (string, []string, error) {
+	policy := sf.config.QueryPolicy
+	if len(policy.DenyObjects) == 0 && len(policy.DenyFields) == 0 {
+		return search, nil, nil
+	}
+
+	checker := &queryPolicyChecker{describeMemo: newDescribeMemo(sf), policy: policy, metadata: true}
+
+	parsed, err := soql.ParseSearch(search)
+	if err != nil {
+		// Searches the policy cannot read are not run
+		var parseErr *soql.ParseError
+		if !errors.As(err, &parseErr) {
+			return "", nil, err
+		}
+		checker.violation(PolicyRuleParse, parseErr.Pos, "", "search cannot be checked: %s", parseErr.Message)
+	} else if err := checker.search(ctx, parsed); err != nil {
+		return "", nil, fmt.Errorf("query policy check failed: %v", err)
+	}
+
+	if len(checker.violations) > 0 {
+		sf.log().WarnContext(ctx, "search rejected by policy", "violations", len(checker.violations))
+		return "", nil, &QueryPolicyError{Org: sf.config.Name, Violations: checker.violations}
+	}
+	if !checker.rewritten {
+		return search, nil, nil
+	}
+
+	search = parsed.String()
+	sf.log().InfoContext(ctx, "search rewritten by policy", "search", search)
+	return search, checker.notes, nil
+}
+
+// queryPolicyChecker walks a parsed query, recording violations and rewriting it in place
+type queryPolicyChecker struct {
+	*describeMemo
+	policy QueryPolicy
+	// metadata is set when objects must be described to follow relationship paths
+	metadata bool
+	// explain is set for queries that are explained rather than run, which only
+	// need the deny lists checked
+	explain    bool
+	violations []QueryPolicyViolation
+	notes      []string
+	rewritten  bool
+}
+
+func (c *queryPolicyChecker) violation(rule string, pos soql.Position, token, format string, args ...any) {
+	c.violations = append(c.violations, QueryPolicyViolation{
+		Rule:    rule,
+		Message: fmt.Sprintf(format, args...),
+		Token:   token,
+		Line:    pos.Line,
+		Column:  pos.Column,
+	})
+}
+
+// query checks a query. Subqueries in the select list read from a child relationship of
+// parent; top level queries and semi-joins have no parent.
+func (c *queryPolicyChecker) query(ctx context.Context, q *soql.Query, parent *SalesforceDescribeResponse) error {
+	if !c.explain {
+		for _, option := range q.For {
+			c.violation(PolicyRuleReadOnly, q.Pos(), option, "FOR %s is not allowed by the read-only query policy", option)
+		}
+		for _, option := range q.Update {
+			c.violation(PolicyRuleReadOnly, q.Pos(), option, "UPDATE %s is not allowed by the read-only query policy", option)
+		}
+		if parent == nil && !q.Subquery {
+			c.limit(q)
+		}
+	}
+	if !c.metadata {
+		return nil
+	}
+
+	// Find the object the query reads from
+	objectName := q.From.Name
+	if parent != nil {
+		objectName = ""
+		for _, relationship := range parent.ChildRelationships {
+			if strings.EqualFold(relationship.RelationshipName, q.From.Name) {
+				objectName = relationship.ChildSObject
+			}
+		}
+		if objectName == "" {
+			// Salesforce rejects the unknown relationship
+			return nil
+		}
+	}
+	if c.policy.objectDenied(objectName) {
+		c.violation(PolicyRuleDenyObject, q.From.Pos(), q.From.Name, "object %s is not allowed", objectName)
+		return nil
+	}
+	object, err := c.describe(ctx, objectName)
+	if err != nil {
+		return err
+	}
+
+	alias := q.From.Alias
+	selected := q.Select[:0]
+	for _, item := range q.Select {
+		keep := true
+		switch item := item.(type) {
+		case *soql.SelectExpr:
+			keep, err = c.selectExpr(ctx, object, alias, item)
+		case *soql.Query:
+			err = c.query(ctx, item, object)
+		case *soql.TypeOf:
+			err = c.typeOf(ctx, object, item)
+		}
+		if err != nil {
+			return err
+		}
+		if keep {
+			selected = append(selected, item)
+		}
+	}
+	if len(selected) == 0 {
+		c.violation(PolicyRuleEmptySelect, q.Pos(), "", "every field selected from %s is denied", objectName)
+	}
+	q.Select = selected
+
+	for _, condition := range []soql.Condition{q.Where, q.Having} {
+		if condition == nil {
+			continue
+		}
+		if err := c.condition(ctx, object, alias, condition); err != nil {
+			return err
+		}
+	}
+
+	var exprs []soql.Expr
+	if q.GroupBy != nil {
+		exprs = append(exprs, q.GroupBy.Exprs...)
+	}
+	for _, item := range q.OrderBy {
+		exprs = append(exprs, item.Expr)
+	}
+	for _, expr := range exprs {
+		if err := c.expr(ctx, object, alias, expr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// search checks the objects and fields of a SOSL RETURNING clause. Without a RETURNING
+// clause a search returns IDs of every searchable object, denied ones included.
+func (c *queryPolicyChecker) search(ctx context.Context, search *soql.Search) error {
+	if len(search.Returning) == 0 && len(c.policy.DenyObjects) > 0 {
+		c.violation(PolicyRuleDenyObject, search.Pos(), "", "a search without RETURNING can return denied objects; list the objects to return")
+		return nil
+	}
+	// Records matched on a denied field reveal its value, so the search must be limited
+	// to a group that leaves such fields out
+	groupSafe := restrictedSearchGroups[search.In]
+	if len(search.Returning) == 0 && len(c.policy.DenyFields) > 0 && !groupSafe {
+		c.violation(PolicyRuleDenyField, search.Pos(), "",
+			"a search without RETURNING can match denied fields; list the objects to return or search IN NAME, EMAIL or PHONE FIELDS")
+		return nil
+	}
+
+	for _, returning := range search.Returning {
+		objectName := returning.Object.Name
+		if c.policy.objectDenied(objectName) {
+			c.violation(PolicyRuleDenyObject, returning.Pos(), objectName, "object %s is not allowed", objectName)
+			continue
+		}
+		if len(returning.Fields) == 0 && (groupSafe || len(c.policy.DenyFields) == 0) {
+			continue
+		}
+		object, err := c.describe(ctx, objectName)
+		if err != nil {
+			return err
+		}
+		if !groupSafe {
+			for _, field := range object.Fields {
+				if c.policy.fieldDenied(object.Name, field.Name) {
+					c.violation(PolicyRuleDenyField, returning.Pos(), objectName,
+						"searching %s can match denied field %s; search IN NAME, EMAIL or PHONE FIELDS", objectName, field.Name)
+					break
+				}
+			}
+		}
+		if len(returning.Fields) == 0 {
+			continue
+		}
+
+		fields := returning.Fields[:0]
+		for _, field := range returning.Fields {
+			keep, err := c.selectExpr(ctx, object, "", field)
+			if err != nil {
+				return err
+			}
+			if keep {
+				fields = append(fields, field)
+			}
+		}
+		if len(fields) == 0 {
+			c.violation(PolicyRuleEmptySelect, returning.Pos(), objectName, "every field returned from %s is denied", objectName)
+		}
+		returning.Fields = fields
+
+		if returning.Where != nil {
+			if err := c.condition(ctx, object, "", returning.Where); err != nil {
+				return err
+			}
+		}
+		for _, item := range returning.OrderBy {
+			if err := c.expr(ctx, object, "", item.Expr); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// limit enforces the maximum LIMIT of the top level query, adding one when it is missing
+func (c *queryPolicyChecker) limit(q *soql.Query) {
+	if c.policy.MaxLimit <= 0 {
+		return
+	}
+
+	switch limit := q.Limit.(type) {
+	case nil:
+		q.Limit = &soql.NumberLiteral{Raw: strconv.Itoa(c.policy.MaxLimit)}
+		c.rewritten = true
+		c.notes = append(c.notes, fmt.Sprintf("added LIMIT %d", c.policy.MaxLimit))
+	case *soql.NumberLiteral:
+		if n, err := strconv.Atoi(limit.Raw); err != nil || n > c.policy.MaxLimit {
+			c.violation(PolicyRuleMaxLimit, limit.Pos(), limit.Raw, "LIMIT %s exceeds the maximum of %d", limit.Raw, c.policy.MaxLimit)
+		}
+	default:
+		c.violation(PolicyRuleMaxLimit, limit.Pos(), limit.String(), "LIMIT must be a number no larger than %d", c.policy.MaxLimit)
+	}
+}
+
+// selectExpr checks a select list item and reports whether it stays in the query.
+// Denied fields are stripped when the policy says so; denied fields inside functions
+// are always rejected.
+func (c *queryPolicyChecker) selectExpr(ctx context.Context, object *SalesforceDescribeResponse, alias string, item *soql.SelectExpr) (bool, error) {
+	ref, ok := item.Expr.(*soql.FieldRef)
+	if !ok {
+		return true, c.expr(ctx, object, alias, item.Expr)
+	}
+
+	denied, err := c.field(ctx, object, alias, ref)
+	if err != nil || denied == "" {
+		return true, err
+	}
+	if c.policy.DeniedFields == DeniedFieldsStrip {
+		c.rewritten = true
+		c.notes = append(c.notes, fmt.Sprintf("removed denied field %s", ref.Name()))
+		return false, nil
+	}
+	c.violation(PolicyRuleDenyField, ref.Pos(), ref.Name(), "field %s is not allowed", denied)
+	return true, nil
+}
+
+// typeOf checks the object types and fields of a TYPEOF expression
+func (c *queryPolicyChecker) typeOf(ctx context.Context, object *SalesforceDescribeResponse, typeOf *soql.TypeOf) error {
+	field := findRelationshipField(object, typeOf.Relationship)
+	if field == nil {
+		return nil
+	}
+
+	// ELSE applies to every type without a WHEN branch
+	var otherTypes []string
+	for _, referenceTo := range field.ReferenceTo {
+		handled := false
+		for _, when := range typeOf.Whens {
+			handled = handled || strings.EqualFold(when.Type, referenceTo)
+		}
+		if !handled {
+			otherTypes = append(otherTypes, referenceTo)
+		}
+	}
+
+	for _, when := range typeOf.Whens {
+		if c.policy.objectDenied(when.Type) {
+			c.violation(PolicyRuleDenyObject, when.TypePos, when.Type, "object %s is not allowed", when.Type)
+			continue
+		}
+		if !containsFold(field.ReferenceTo, when.Type) {
+			continue
+		}
+		target, err := c.describe(ctx, when.Type)
+		if err != nil {
+			return err
+		}
+		for _, ref := range when.Fields {
+			denied, err := c.field(ctx, target, "", ref)
+			if err != nil {
+				return err
+			}
+			if denied != "" {
+				c.violation(PolicyRuleDenyField, ref.Pos(), ref.Name(), "field %s is not allowed", denied)
+			}
+		}
+	}
+
+	if len(typeOf.Else) == 0 {
+		return nil
+	}
+	for _, objectType := range otherTypes {
+		if c.policy.objectDenied(objectType) {
+			c.violation(PolicyRuleDenyObject, typeOf.RelationshipPos, typeOf.Relationship,
+				"TYPEOF %s ELSE can read object %s, which is not allowed", typeOf.Relationship, objectType)
+			continue
+		}
+		for _, ref := range typeOf.Else {
+			if c.policy.fieldDenied(objectType, ref.Path[len(ref.Path)-1]) {
+				c.violation(PolicyRuleDenyField, ref.Pos(), ref.Name(), "field %s.%s is not allowed", objectType, ref.Name())
+			}
+		}
+	}
+	return nil
+}
+
+// condition checks the fields and semi-joins of a WHERE or HAVING condition. Filtering on
+// a denied field would leak its values, so it is always rejected.
+func (c *queryPolicyChecker) condition(ctx context.Context, object *SalesforceDescribeResponse, alias string, condition soql.Condition) error {
+	switch condition := condition.(type) {
+	case *soql.Comparison:
+		if err := c.expr(ctx, object, alias, condition.Left); err != nil {
+			return err
+		}
+		if subquery, ok := condition.Right.(*soql.Query); ok {
+			return c.query(ctx, subquery, nil)
+		}
+	case *soql.LogicalCondition:
+		for _, nested := range condition.Conditions {
+			if err := c.condition(ctx, object, alias, nested); err != nil {
+				return err
+			}
+		}
+	case *soql.NotCondition:
+		return c.condition(ctx, object, alias, condition.Condition)
+	}
+	return nil
+}
+
+// expr rejects denied fields in filters, groupings, orderings and function arguments
+func (c *queryPolicyChecker) expr(ctx context.Context, object *SalesforceDescribeResponse, alias string, expr soql.Expr) error {
+	switch expr := expr.(type) {
+	case *soql.FieldRef:
+		denied, err := c.field(ctx, object, alias, expr)
+		if err != nil {
+			return err
+		}
+		if denied != "" {
+			c.violation(PolicyRuleDenyField, expr.Pos(), expr.Name(), "field %s is not allowed", denied)
+		}
+	case *soql.FunctionCall:
+		if strings.EqualFold(expr.Name, "FIELDS") {
+			// FIELDS(ALL) and friends would select every denied field of the object
+			for _, field := range object.Fields {
+				if c.policy.fieldDenied(object.Name, field.Name) {
+					c.violation(PolicyRuleDenyField, expr.Pos(), expr.String(),
+						"%s would select %s.%s, which is not allowed; list the fields instead", expr, object.Name, field.Name)
+					break
+				}
+			}
+			return nil
+		}
+		for _, arg := range expr.Args {
+			if argExpr, ok := arg.(soql.Expr); ok {
+				if err := c.expr(ctx, object, alias, argExpr); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// field follows a relationship path from object. Relationships to denied objects are
+// reported as violations; a denied field is returned as Object.Field. Paths through
+// polymorphic relationships can reach any of their types, so each one is checked.
+func (c *queryPolicyChecker) field(ctx context.Context, object *SalesforceDescribeResponse, alias string, ref *soql.FieldRef) (string, error) {
+	path, positions := ref.Path, ref.PathPos
+	if alias != "" && len(path) > 1 && strings.EqualFold(path[0], alias) {
+		path, positions = path[1:], positions[1:]
+	}
+
+	current := object
+	for i, name := range path[:len(path)-1] {
+		relationship := findRelationshipField(current, name)
+		if relationship == nil {
+			// Salesforce rejects the unknown relationship
+			return "", nil
+		}
+		for _, referenceTo := range relationship.ReferenceTo {
+			if c.policy.objectDenied(referenceTo) {
+				c.violation(PolicyRuleDenyObject, positions[i], name,
+					"relationship %s.%s reaches object %s, which is not allowed", current.Name, name, referenceTo)
+				return "", nil
+			}
+		}
+
+		if len(relationship.ReferenceTo) != 1 {
+			// The rest of the path is read from whichever type the record references
+			last := path[len(path)-1]
+			for _, referenceTo := range relationship.ReferenceTo {
+				if c.policy.fieldDenied(referenceTo, last) {
+					return referenceTo + "." + last, nil
+				}
+			}
+			return "", nil
+		}
+		next, err := c.describe(ctx, relationship.ReferenceTo[0])
+		if err != nil {
+			return "", err
+		}
+		current = next
+	}
+
+	last := path[len(path)-1]
+	if c.policy.fieldDenied(current.Name, last) {
+		return current.Name + "." + last, nil
+	}
+	return "", nil
+}
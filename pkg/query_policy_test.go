@@ -0,0 +1,364 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// policyTestCase is a query or search checked against a policy. want is the text sent to
+// Salesforce; rules lists the violated rules when the query is rejected.
+type policyTestCase struct {
+	name   string
+	policy QueryPolicy
+	query  string
+	want   string
+	notes  []string
+	rules  []string
+}
+
+func runPolicyTests(t *testing.T, tests []policyTestCase, apply func(*SalesforceClient, context.Context, string) (string, []string, error)) {
+	t.Helper()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sf := newDescribeTestClient(t)
+			sf.config.QueryPolicy = test.policy
+
+			query, notes, err := apply(sf, context.Background(), test.query)
+			if len(test.rules) > 0 {
+				var policyErr *QueryPolicyError
+				if !errors.As(err, &policyErr) {
+					t.Fatalf("got %q, %v, want a *QueryPolicyError", query, err)
+				}
+				var rules []string
+				for _, violation := range policyErr.Violations {
+					rules = append(rules, violation.Rule)
+				}
+				if !reflect.DeepEqual(rules, test.rules) {
+					t.Errorf("rules = %q, want %q (%v)", rules, test.rules, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if query != test.want {
+				t.Errorf("query = %q, want %q", query, test.want)
+			}
+			if !reflect.DeepEqual(notes, test.notes) {
+				t.Errorf("notes = %q, want %q", notes, test.notes)
+			}
+		})
+	}
+}
+
+func TestApplyQueryPolicy(t *testing.T) {
+	maxLimit := QueryPolicy{MaxLimit: 100, DeniedFields: DeniedFieldsBlock}
+	denyUser := QueryPolicy{DenyObjects: []string{"User"}, DeniedFields: DeniedFieldsBlock}
+	blockSSN := QueryPolicy{DenyFields: []string{"Contact.SSN__c"}, DeniedFields: DeniedFieldsBlock}
+	stripSSN := QueryPolicy{DenyFields: []string{"SSN__c", "User.Email"}, DeniedFields: DeniedFieldsStrip}
+
+	runPolicyTests(t, []policyTestCase{
+		{
+			name:  "policy disabled",
+			query: "select id from Account where Name = 'a'",
+			want:  "select id from Account where Name = 'a'",
+		},
+		{
+			name:  "for update rejected without a policy",
+			query: "SELECT Id FROM Account FOR UPDATE",
+			rules: []string{PolicyRuleReadOnly},
+		},
+		{
+			name:  "for view rejected without a policy",
+			query: "SELECT Id FROM Account FOR VIEW",
+			rules: []string{PolicyRuleReadOnly},
+		},
+		{
+			name:  "unparsable locking query rejected without a policy",
+			query: "SELECT Id FROM Account WHERE Name = 'a' ~ FOR UPDATE",
+			rules: []string{PolicyRuleReadOnly},
+		},
+		{
+			name:  "unparsable query runs without a policy",
+			query: "SELECT Id FROM Account WHERE Name ~ 'a'",
+			want:  "SELECT Id FROM Account WHERE Name ~ 'a'",
+		},
+		{
+			name:   "limit injected",
+			policy: maxLimit,
+			query:  "select Id from Account",
+			want:   "SELECT Id FROM Account LIMIT 100",
+			notes:  []string{"added LIMIT 100"},
+		},
+		{
+			name:   "limit within maximum is kept",
+			policy: maxLimit,
+			query:  "select Id from Account limit 50",
+			want:   "select Id from Account limit 50",
+		},
+		{
+			name:   "limit over maximum",
+			policy: maxLimit,
+			query:  "SELECT Id FROM Account LIMIT 500",
+			rules:  []string{PolicyRuleMaxLimit},
+		},
+		{
+			name:   "bind variable limit",
+			policy: maxLimit,
+			query:  "SELECT Id FROM Account LIMIT :n",
+			rules:  []string{PolicyRuleMaxLimit},
+		},
+		{
+			name:   "subquery limits are left alone",
+			policy: maxLimit,
+			query:  "SELECT Id, (SELECT Id FROM Contacts) FROM Account LIMIT 10",
+			want:   "SELECT Id, (SELECT Id FROM Contacts) FROM Account LIMIT 10",
+		},
+		{
+			name:   "for update rejected",
+			policy: maxLimit,
+			query:  "SELECT Id FROM Account LIMIT 1 FOR UPDATE",
+			rules:  []string{PolicyRuleReadOnly},
+		},
+		{
+			name:   "update tracking rejected",
+			policy: maxLimit,
+			query:  "SELECT Id FROM Account LIMIT 1 UPDATE TRACKING",
+			rules:  []string{PolicyRuleReadOnly},
+		},
+		{
+			name:   "unparsable query rejected",
+			policy: maxLimit,
+			query:  "SELECT Id FROM Account WHERE",
+			rules:  []string{PolicyRuleParse},
+		},
+		{
+			name:   "denied object",
+			policy: denyUser,
+			query:  "SELECT Id FROM User",
+			rules:  []string{PolicyRuleDenyObject},
+		},
+		{
+			name:   "denied object through relationship path",
+			policy: denyUser,
+			query:  "SELECT Name, Owner.Name FROM Account",
+			rules:  []string{PolicyRuleDenyObject},
+		},
+		{
+			name:   "denied object in semi-join",
+			policy: denyUser,
+			query:  "SELECT Id FROM Account WHERE OwnerId IN (SELECT Id FROM User)",
+			rules:  []string{PolicyRuleDenyObject},
+		},
+		{
+			name:   "allowed objects",
+			policy: denyUser,
+			query:  "SELECT Id, Account.Name FROM Contact",
+			want:   "SELECT Id, Account.Name FROM Contact",
+		},
+		{
+			name:   "denied field blocked",
+			policy: blockSSN,
+			query:  "SELECT Id, SSN__c FROM Contact",
+			rules:  []string{PolicyRuleDenyField},
+		},
+		{
+			name:   "denied field in child subquery blocked",
+			policy: blockSSN,
+			query:  "SELECT Id, (SELECT SSN__c FROM Contacts) FROM Account",
+			rules:  []string{PolicyRuleDenyField},
+		},
+		{
+			name:   "fields function reaching a denied field",
+			policy: blockSSN,
+			query:  "SELECT FIELDS(ALL) FROM Contact LIMIT 200",
+			rules:  []string{PolicyRuleDenyField},
+		},
+		{
+			name:   "object scoped deny does not apply to other objects",
+			policy: QueryPolicy{DenyFields: []string{"Contact.Email"}, DeniedFields: DeniedFieldsBlock},
+			query:  "SELECT Email FROM User",
+			want:   "SELECT Email FROM User",
+		},
+		{
+			name:   "denied field stripped",
+			policy: stripSSN,
+			query:  "SELECT Id, SSN__c FROM Contact",
+			want:   "SELECT Id FROM Contact",
+			notes:  []string{"removed denied field SSN__c"},
+		},
+		{
+			name:   "denied field stripped through relationship path",
+			policy: stripSSN,
+			query:  "SELECT Name, Owner.Email FROM Account",
+			want:   "SELECT Name FROM Account",
+			notes:  []string{"removed denied field Owner.Email"},
+		},
+		{
+			name:   "denied field in where is rejected when stripping",
+			policy: stripSSN,
+			query:  "SELECT Id FROM Contact WHERE SSN__c = '123'",
+			rules:  []string{PolicyRuleDenyField},
+		},
+		{
+			name:   "every field stripped",
+			policy: stripSSN,
+			query:  "SELECT SSN__c FROM Contact",
+			rules:  []string{PolicyRuleEmptySelect},
+		},
+	}, (*SalesforceClient).applyQueryPolicy)
+}
+
+func TestApplyExplainPolicy(t *testing.T) {
+	denyUser := QueryPolicy{DenyObjects: []string{"User"}, DeniedFields: DeniedFieldsBlock}
+	blockSSN := QueryPolicy{DenyFields: []string{"SSN__c"}, DeniedFields: DeniedFieldsBlock}
+	stripSSN := QueryPolicy{DenyFields: []string{"SSN__c"}, DeniedFields: DeniedFieldsStrip}
+
+	runPolicyTests(t, []policyTestCase{
+		{
+			name:   "max limit does not apply to explain",
+			policy: QueryPolicy{MaxLimit: 10, DeniedFields: DeniedFieldsBlock},
+			query:  "SELECT Id FROM Account",
+			want:   "SELECT Id FROM Account",
+		},
+		{
+			name:  "locking clauses are not run by explain",
+			query: "SELECT Id FROM Account FOR UPDATE",
+			want:  "SELECT Id FROM Account FOR UPDATE",
+		},
+		{
+			name:   "denied object",
+			policy: denyUser,
+			query:  "SELECT Id FROM Account WHERE Owner.Name = 'a'",
+			rules:  []string{PolicyRuleDenyObject},
+		},
+		{
+			name:   "denied field in where",
+			policy: blockSSN,
+			query:  "SELECT Id FROM Contact WHERE SSN__c = '123-45-6789'",
+			rules:  []string{PolicyRuleDenyField},
+		},
+		{
+			name:   "denied field stripped",
+			policy: stripSSN,
+			query:  "SELECT Id, SSN__c FROM Contact",
+			want:   "SELECT Id FROM Contact",
+			notes:  []string{"removed denied field SSN__c"},
+		},
+		{
+			name:   "unparsable query with deny lists",
+			policy: denyUser,
+			query:  "SELECT Id FROM Account WHERE Name ~ 'a'",
+			rules:  []string{PolicyRuleParse},
+		},
+	}, (*SalesforceClient).applyExplainPolicy)
+}
+
+func TestApplySearchPolicy(t *testing.T) {
+	denyUser := QueryPolicy{DenyObjects: []string{"User"}, DeniedFields: DeniedFieldsBlock}
+	blockSSN := QueryPolicy{DenyFields: []string{"SSN__c"}, DeniedFields: DeniedFieldsBlock}
+	stripSSN := QueryPolicy{DenyFields: []string{"SSN__c"}, DeniedFields: DeniedFieldsStrip}
+
+	runPolicyTests(t, []policyTestCase{
+		{
+			name:   "max limit does not apply to searches",
+			policy: QueryPolicy{MaxLimit: 10, DeniedFields: DeniedFieldsBlock},
+			query:  "FIND {Acme} RETURNING Account(Name)",
+			want:   "FIND {Acme} RETURNING Account(Name)",
+		},
+		{
+			name:   "allowed returning clause",
+			policy: denyUser,
+			query:  "FIND {Acme} IN NAME FIELDS RETURNING Account(Name WHERE Industry = 'Tech' ORDER BY Name LIMIT 5), Contact WITH DIVISION = 'Global' LIMIT 20",
+			want:   "FIND {Acme} IN NAME FIELDS RETURNING Account(Name WHERE Industry = 'Tech' ORDER BY Name LIMIT 5), Contact WITH DIVISION = 'Global' LIMIT 20",
+		},
+		{
+			name:   "denied object",
+			policy: denyUser,
+			query:  "FIND {Acme} RETURNING Account(Name), User(Name)",
+			rules:  []string{PolicyRuleDenyObject},
+		},
+		{
+			name:   "denied object through relationship path",
+			policy: denyUser,
+			query:  "FIND {Acme} RETURNING Account(Name, Owner.Name)",
+			rules:  []string{PolicyRuleDenyObject},
+		},
+		{
+			name:   "search without returning",
+			policy: denyUser,
+			query:  "FIND {Acme}",
+			rules:  []string{PolicyRuleDenyObject},
+		},
+		{
+			name:   "search without returning and only denied fields",
+			policy: blockSSN,
+			query:  "FIND {Acme}",
+			rules:  []string{PolicyRuleDenyField},
+		},
+		{
+			name:   "search of name fields without returning and only denied fields",
+			policy: blockSSN,
+			query:  "FIND {Acme} IN NAME FIELDS",
+			want:   "FIND {Acme} IN NAME FIELDS",
+		},
+		{
+			name:   "search of all fields of an object with denied fields",
+			policy: blockSSN,
+			query:  "FIND {123-45-6789} IN ALL FIELDS RETURNING Contact(Id)",
+			rules:  []string{PolicyRuleDenyField},
+		},
+		{
+			name:   "default search group of an object with denied fields",
+			policy: blockSSN,
+			query:  "FIND {123-45-6789} RETURNING Contact",
+			rules:  []string{PolicyRuleDenyField},
+		},
+		{
+			name:   "search of all fields of an object without denied fields",
+			policy: blockSSN,
+			query:  "FIND {Acme} IN ALL FIELDS RETURNING Account(Name)",
+			want:   "FIND {Acme} IN ALL FIELDS RETURNING Account(Name)",
+		},
+		{
+			name:   "search of email fields of an object with denied fields",
+			policy: blockSSN,
+			query:  "FIND {a@example.com} IN EMAIL FIELDS RETURNING Contact(Id)",
+			want:   "FIND {a@example.com} IN EMAIL FIELDS RETURNING Contact(Id)",
+		},
+		{
+			name:   "second returning clause",
+			policy: blockSSN,
+			query:  "FIND {Acme} WITH DIVISION = 'Global' RETURNING Contact(SSN__c)",
+			rules:  []string{PolicyRuleParse},
+		},
+		{
+			name:   "denied field blocked",
+			policy: blockSSN,
+			query:  "FIND {Acme} IN NAME FIELDS RETURNING Contact(Id, SSN__c)",
+			rules:  []string{PolicyRuleDenyField},
+		},
+		{
+			name:   "denied field stripped",
+			policy: stripSSN,
+			query:  "FIND {Acme*} IN NAME FIELDS RETURNING Contact(Id, SSN__c), Account",
+			want:   "FIND {Acme*} IN NAME FIELDS RETURNING Contact(Id), Account",
+			notes:  []string{"removed denied field SSN__c"},
+		},
+		{
+			name:   "denied field in where is rejected when stripping",
+			policy: stripSSN,
+			query:  "FIND {Acme} IN NAME FIELDS RETURNING Contact(Id WHERE SSN__c != null)",
+			rules:  []string{PolicyRuleDenyField},
+		},
+		{
+			name:   "every field stripped",
+			policy: stripSSN,
+			query:  "FIND {Acme} IN NAME FIELDS RETURNING Contact(SSN__c)",
+			rules:  []string{PolicyRuleEmptySelect},
+		},
+	}, (*SalesforceClient).applySearchPolicy)
+}
@@ -0,0 +1,176 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Record write operations
+const (
+	RecordCreate = "create"
+	RecordUpdate = "update"
+	RecordUpsert = "upsert"
+	RecordDelete = "delete"
+)
+
+// SalesforceSaveResult represents the response from an sObject create or upsert
+type SalesforceSaveResult struct {
+	ID      string            `json:"id"`
+	Success bool              `json:"success"`
+	Created bool              `json:"created"`
+	Errors  []SalesforceError `json:"errors"`
+}
+
+// sobjectURL builds an sObject REST resource URL from escaped path segments
+func (sf *SalesforceClient) sobjectURL(segments ...string) (string, error) {
+	escaped := make([]string, len(segments))
+	for i, segment := range segments {
+		escaped[i] = url.PathEscape(segment)
+	}
+	return sf.dataURL("sobjects/" + strings.Join(escaped, "/"))
+}
+
+// CreateRecord inserts a new record and returns its ID
+func (sf *SalesforceClient) CreateRecord(ctx context.Context, objectType string, fields map[string]interface{}) (*SalesforceSaveResult, error) {
+	createURL, err := sf.sobjectURL(objectType)
+	if err != nil {
+		return nil, err
+	}
+
+	var result SalesforceSaveResult
+	if err := sf.requestJSON(ctx, "POST", createURL, "create", fields, &result); err != nil {
+		return nil, err
+	}
+	result.Created = true
+	return &result, nil
+}
+
+// UpdateRecord updates the given fields of an existing record
+func (sf *SalesforceClient) UpdateRecord(ctx context.Context, objectType, id string, fields map[string]interface{}) error {
+	updateURL, err := sf.sobjectURL(objectType, id)
+	if err != nil {
+		return err
+	}
+	return sf.requestJSON(ctx, "PATCH", updateURL, "update", fields, nil)
+}
+
+// UpsertRecord creates or updates the record matching an external ID value
+func (sf *SalesforceClient) UpsertRecord(ctx context.Context, objectType, externalIDField, externalID string, fields map[string]interface{}) (*SalesforceSaveResult, error) {
+	upsertURL, err := sf.sobjectURL(objectType, externalIDField, externalID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Older API versions answer an update with 204 No Content, leaving the result empty
+	result := SalesforceSaveResult{Success: true}
+	if err := sf.requestJSON(ctx, "PATCH", upsertURL, "upsert", fields, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteRecord deletes a record, moving it to the recycle bin
+func (sf *SalesforceClient) DeleteRecord(ctx context.Context, objectType, id string) error {
+	deleteURL, err := sf.sobjectURL(objectType, id)
+	if err != nil {
+		return err
+	}
+	return sf.requestJSON(ctx, "DELETE", deleteURL, "delete", nil, nil)
+}
+
+// ValidateRecordWrite checks a write against describe metadata without calling the API,
+// returning every problem found. id is checked for update and delete, externalIDField
+// for upsert.
+func ValidateRecordWrite(describe *SalesforceDescribeResponse, operation string, fields map[string]interface{}, id, externalIDField string) []string {
+	var problems []string
+
+	// Object level permissions
+	switch operation {
+	case RecordCreate:
+		if !describe.Createable {
+			problems = append(problems, fmt.Sprintf("%s is not createable", describe.Name))
+		}
+	case RecordUpdate:
+		if !describe.Updateable {
+			problems = append(problems, fmt.Sprintf("%s is not updateable", describe.Name))
+		}
+	case RecordUpsert:
+		if !describe.Createable || !describe.Updateable {
+			problems = append(problems, fmt.Sprintf("%s must be createable and updateable to upsert", describe.Name))
+		}
+	case RecordDelete:
+		if !describe.Deletable {
+			problems = append(problems, fmt.Sprintf("%s is not deletable", describe.Name))
+		}
+	}
+
+	// Record ID must belong to the object
+	if (operation == RecordUpdate || operation == RecordDelete) && describe.KeyPrefix != "" {
+		if len(id) != 15 && len(id) != 18 {
+			problems = append(problems, fmt.Sprintf("%q is not a 15 or 18 character record ID", id))
+		} else if !strings.HasPrefix(id, describe.KeyPrefix) {
+			problems = append(problems, fmt.Sprintf("record ID %s does not belong to %s (key prefix %s)", id, describe.Name, describe.KeyPrefix))
+		}
+	}
+
+	describeFields := map[string]SalesforceDescribeField{}
+	for _, field := range describe.Fields {
+		describeFields[strings.ToLower(field.Name)] = field
+	}
+
+	if operation == RecordUpsert {
+		field, ok := describeFields[strings.ToLower(externalIDField)]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("external ID field %s does not exist on %s", externalIDField, describe.Name))
+		} else if !field.ExternalID && !field.IDLookup {
+			problems = append(problems, fmt.Sprintf("%s is not an external ID field", field.Name))
+		}
+	}
+
+	// Field level permissions
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		field, ok := describeFields[strings.ToLower(name)]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("field %s does not exist on %s", name, describe.Name))
+			continue
+		}
+		switch operation {
+		case RecordCreate:
+			if !field.Createable {
+				problems = append(problems, fmt.Sprintf("field %s is not createable", field.Name))
+			}
+		case RecordUpdate:
+			if !field.Updateable {
+				problems = append(problems, fmt.Sprintf("field %s is not updateable", field.Name))
+			}
+		case RecordUpsert:
+			if !field.Createable && !field.Updateable {
+				problems = append(problems, fmt.Sprintf("field %s is neither createable nor updateable", field.Name))
+			}
+		}
+	}
+
+	// Required fields on create
+	if operation == RecordCreate {
+		provided := map[string]bool{}
+		for name := range fields {
+			provided[strings.ToLower(name)] = true
+		}
+		for _, field := range describe.Fields {
+			if field.Createable && !field.Nillable && !field.DefaultedOnCreate && field.Type != "boolean" &&
+				!provided[strings.ToLower(field.Name)] {
+				problems = append(problems, fmt.Sprintf("required field %s is missing", field.Name))
+			}
+		}
+	}
+
+	return problems
+}
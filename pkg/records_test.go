@@ -0,0 +1,160 @@
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// testWriteDescribes is the metadata served by newRecordTestClient
+var testWriteDescribes = map[string]*SalesforceDescribeResponse{
+	"Account": {
+		Name: "Account", KeyPrefix: "001", Createable: true, Updateable: true, Deletable: true,
+		Fields: []SalesforceDescribeField{
+			{Name: "Id", Type: "id", IDLookup: true},
+			{Name: "Name", Type: "string", Createable: true, Updateable: true},
+			{Name: "Industry", Type: "picklist", Createable: true, Updateable: true, Nillable: true},
+			{Name: "External_Id__c", Type: "string", Createable: true, Updateable: true, Nillable: true, ExternalID: true},
+			{Name: "IsPartner__c", Type: "boolean", Createable: true, Updateable: true},
+			{Name: "Region__c", Type: "string", Createable: true, Nillable: true},
+			{Name: "OwnerId", Type: "reference", Createable: true, Updateable: true, DefaultedOnCreate: true},
+			{Name: "CreatedDate", Type: "datetime", Nillable: true},
+		},
+	},
+	"AccountHistory": {
+		Name: "AccountHistory", KeyPrefix: "017",
+		Fields: []SalesforceDescribeField{{Name: "Id", Type: "id", IDLookup: true}},
+	},
+}
+
+// newRecordTestClient returns a client for a test server that serves describes from
+// testWriteDescribes, recording the method of every request it receives
+func newRecordTestClient(t *testing.T) (*SalesforceClient, *[]string) {
+	t.Helper()
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		for name, describe := range testWriteDescribes {
+			if r.Method == "GET" && r.URL.Path == "/services/data/v62.0/sobjects/"+name+"/describe" {
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(describe)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`[{"message":"The requested resource does not exist","errorCode":"NOT_FOUND"}]`))
+	}))
+	t.Cleanup(server.Close)
+
+	sf := NewSalesforceClient(&OrgConfig{Name: "test"})
+	sf.auth = &SalesforceAuth{AccessToken: "token", InstanceURL: server.URL}
+	sf.version = "v62.0"
+	return sf, &methods
+}
+
+func TestValidateRecordWrite(t *testing.T) {
+	tests := []struct {
+		name            string
+		object          string
+		operation       string
+		fields          map[string]interface{}
+		id              string
+		externalIDField string
+		problems        []string
+	}{
+		{
+			name:      "valid create",
+			object:    "Account",
+			operation: RecordCreate,
+			fields:    map[string]interface{}{"Name": "Acme", "Industry": "Tech"},
+		},
+		{
+			name:      "create without required field",
+			object:    "Account",
+			operation: RecordCreate,
+			fields:    map[string]interface{}{"Industry": "Tech"},
+			problems:  []string{"required field Name is missing"},
+		},
+		{
+			name:      "create with unknown and read only fields",
+			object:    "Account",
+			operation: RecordCreate,
+			fields:    map[string]interface{}{"Name": "Acme", "Nmae": "Acme", "CreatedDate": "2026-01-01T00:00:00Z"},
+			problems:  []string{"field CreatedDate is not createable", "field Nmae does not exist on Account"},
+		},
+		{
+			name:      "create on a read only object",
+			object:    "AccountHistory",
+			operation: RecordCreate,
+			fields:    map[string]interface{}{},
+			problems:  []string{"AccountHistory is not createable"},
+		},
+		{
+			name:      "valid update",
+			object:    "Account",
+			operation: RecordUpdate,
+			fields:    map[string]interface{}{"Industry": "Tech"},
+			id:        "001000000000001AAA",
+		},
+		{
+			name:      "update of a create only field",
+			object:    "Account",
+			operation: RecordUpdate,
+			fields:    map[string]interface{}{"Region__c": "EMEA"},
+			id:        "001000000000001",
+			problems:  []string{"field Region__c is not updateable"},
+		},
+		{
+			name:      "update with another object's ID",
+			object:    "Account",
+			operation: RecordUpdate,
+			fields:    map[string]interface{}{"Industry": "Tech"},
+			id:        "003000000000001AAA",
+			problems:  []string{"record ID 003000000000001AAA does not belong to Account (key prefix 001)"},
+		},
+		{
+			name:      "delete with a malformed ID",
+			object:    "Account",
+			operation: RecordDelete,
+			id:        "001",
+			problems:  []string{`"001" is not a 15 or 18 character record ID`},
+		},
+		{
+			name:            "valid upsert",
+			object:          "Account",
+			operation:       RecordUpsert,
+			fields:          map[string]interface{}{"Name": "Acme"},
+			externalIDField: "External_Id__c",
+		},
+		{
+			name:            "upsert on a field that is not an external ID",
+			object:          "Account",
+			operation:       RecordUpsert,
+			fields:          map[string]interface{}{"Name": "Acme"},
+			externalIDField: "Name",
+			problems:        []string{"Name is not an external ID field"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sf, methods := newRecordTestClient(t)
+
+			// The dry run path of the record write tools: describe, then validate
+			describe, err := sf.Describe(context.Background(), test.object, false)
+			if err != nil {
+				t.Fatalf("Describe failed: %v", err)
+			}
+			problems := ValidateRecordWrite(describe, test.operation, test.fields, test.id, test.externalIDField)
+			if !reflect.DeepEqual(problems, test.problems) {
+				t.Errorf("problems = %q, want %q", problems, test.problems)
+			}
+			if !reflect.DeepEqual(*methods, []string{"GET"}) {
+				t.Errorf("requests = %q, want only the describe GET", *methods)
+			}
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/zhongxiao37/soql-mcp/pkg"
 )
 
 // CreateTermsResource creates a new terms resource
@@ -29,7 +30,7 @@ func TermsResourceHandler(ctx context.Context, request mcp.ReadResourceRequest)
 
 	// Extract the file path from the URI
 	filePath := parsedURI.Path
-	fmt.Println("filePath", filePath)
+	pkg.LoggerFromContext(ctx).Debug("reading terms resource", "path", filePath)
 
 	// Read the file using the extracted path
 	content, err := os.ReadFile(filePath)
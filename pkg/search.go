@@ -0,0 +1,102 @@
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// SalesforceSearchResponse represents the response from a SOSL search
+type SalesforceSearchResponse struct {
+	SearchRecords []interface{} `json:"searchRecords"`
+	// PolicyNotes describes how the org's query policy rewrote the search
+	PolicyNotes []string `json:"-"`
+}
+
+// Search executes a SOSL search against Salesforce
+func (sf *SalesforceClient) Search(ctx context.Context, search string) (*SalesforceSearchResponse, error) {
+	search, policyNotes, err := sf.applySearchPolicy(ctx, search)
+	if err != nil {
+		return nil, err
+	}
+
+	// URL encode the search
+	params := url.Values{}
+	params.Add("q", search)
+	searchURL, err := sf.dataURL("search?" + params.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	var result SalesforceSearchResponse
+	if err := sf.getJSON(ctx, searchURL, "search", &result); err != nil {
+		return nil, err
+	}
+	result.PolicyNotes = policyNotes
+
+	return &result, nil
+}
+
+// GroupByObject groups search results by sObject type, in the shape of query results
+func (result *SalesforceSearchResponse) GroupByObject() map[string]*SalesforceQueryResponse {
+	groups := map[string]*SalesforceQueryResponse{}
+	for _, record := range result.SearchRecords {
+		objectType := "Unknown"
+		if recordMap, ok := record.(map[string]interface{}); ok {
+			if attributes, ok := recordMap["attributes"].(map[string]interface{}); ok {
+				if t, ok := attributes["type"].(string); ok {
+					objectType = t
+				}
+			}
+		}
+
+		group, ok := groups[objectType]
+		if !ok {
+			group = &SalesforceQueryResponse{Done: true, PagesFetched: 1}
+			groups[objectType] = group
+		}
+		group.Records = append(group.Records, record)
+		group.TotalSize++
+	}
+	return groups
+}
+
+// FormatSearchAsTable formats search results as one table per sObject type
+func FormatSearchAsTable(result *SalesforceSearchResponse) string {
+	var notes bytes.Buffer
+	for _, note := range result.PolicyNotes {
+		notes.WriteString(fmt.Sprintf("Query policy: %s\n", note))
+	}
+	if len(result.SearchRecords) == 0 {
+		return notes.String() + "No records found."
+	}
+
+	groups := result.GroupByObject()
+	objectTypes := make([]string, 0, len(groups))
+	for objectType := range groups {
+		objectTypes = append(objectTypes, objectType)
+	}
+	sort.Strings(objectTypes)
+
+	var buffer bytes.Buffer
+	buffer.WriteString(notes.String())
+	for _, objectType := range objectTypes {
+		buffer.WriteString(strings.Repeat("=", 50) + "\n")
+		buffer.WriteString(fmt.Sprintf("Object: %s\n", objectType))
+		buffer.WriteString(strings.Repeat("=", 50) + "\n")
+		buffer.WriteString(FormatAsTable(groups[objectType]))
+	}
+
+	return buffer.String()
+}
+
+// FormatSearchAsJSON formats search results as JSON keyed by sObject type. Policy notes are
+// not included since every key is an sObject type.
+func FormatSearchAsJSON(result *SalesforceSearchResponse) string {
+	jsonBytes, _ := json.MarshalIndent(result.GroupByObject(), "", "  ")
+	return string(jsonBytes)
+}
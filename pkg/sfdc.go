@@ -2,30 +2,45 @@ package pkg
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 )
 
 // SalesforceAuth represents OAuth response from Salesforce
 type SalesforceAuth struct {
-	AccessToken string `json:"access_token"`
-	InstanceURL string `json:"instance_url"`
-	ID          string `json:"id"`
-	TokenType   string `json:"token_type"`
-	IssuedAt    string `json:"issued_at"`
-	Signature   string `json:"signature"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	InstanceURL  string `json:"instance_url"`
+	ID           string `json:"id"`
+	TokenType    string `json:"token_type"`
+	IssuedAt     string `json:"issued_at"`
+	Signature    string `json:"signature"`
 }
 
+// DefaultMaxRecords is the number of records a query returns when no cap is given
+const DefaultMaxRecords = 10000
+
 // SalesforceQueryResponse represents the response from SOQL query
 type SalesforceQueryResponse struct {
-	TotalSize int           `json:"totalSize"`
-	Done      bool          `json:"done"`
-	Records   []interface{} `json:"records"`
+	TotalSize      int           `json:"totalSize"`
+	Done           bool          `json:"done"`
+	NextRecordsURL string        `json:"nextRecordsUrl,omitempty"`
+	Records        []interface{} `json:"records"`
+	// Pagination details filled in by the client, not returned by Salesforce
+	PagesFetched      int  `json:"pagesFetched"`
+	MaxRecordsReached bool `json:"maxRecordsReached"`
+	// PolicyNotes describes how the org's query policy rewrote the query
+	PolicyNotes []string `json:"policyNotes,omitempty"`
+	// Warnings holds problems noticed before the query ran that did not stop it
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 // SalesforceError represents error response from Salesforce
@@ -53,171 +68,498 @@ type SalesforceDescribeField struct {
 	Createable     bool                     `json:"createable"`
 	DefaultValue   interface{}              `json:"defaultValue"`
 	PicklistValues []map[string]interface{} `json:"picklistValues"`
+	// Relationship metadata
+	ReferenceTo      []string `json:"referenceTo"`
+	RelationshipName string   `json:"relationshipName"`
+	// Field details
+	Nillable          bool   `json:"nillable"`
+	DefaultedOnCreate bool   `json:"defaultedOnCreate"`
+	Calculated        bool   `json:"calculated"`
+	CalculatedFormula string `json:"calculatedFormula"`
+	ExternalID        bool   `json:"externalId"`
+	IDLookup          bool   `json:"idLookup"`
+	Filterable        bool   `json:"filterable"`
+	Sortable          bool   `json:"sortable"`
+	Groupable         bool   `json:"groupable"`
+	Precision         int    `json:"precision"`
+	Scale             int    `json:"scale"`
+	Digits            int    `json:"digits"`
+	InlineHelpText    string `json:"inlineHelpText"`
+}
+
+// SalesforceChildRelationship represents a relationship from another object to the described object
+type SalesforceChildRelationship struct {
+	ChildSObject     string `json:"childSObject"`
+	Field            string `json:"field"`
+	RelationshipName string `json:"relationshipName"`
+	CascadeDelete    bool   `json:"cascadeDelete"`
+}
+
+// SalesforceRecordTypeInfo represents a record type available on the described object
+type SalesforceRecordTypeInfo struct {
+	Name                     string `json:"name"`
+	DeveloperName            string `json:"developerName"`
+	RecordTypeID             string `json:"recordTypeId"`
+	Active                   bool   `json:"active"`
+	Available                bool   `json:"available"`
+	DefaultRecordTypeMapping bool   `json:"defaultRecordTypeMapping"`
+	Master                   bool   `json:"master"`
 }
 
 // SalesforceDescribeResponse represents the response from Salesforce describe API
 type SalesforceDescribeResponse struct {
-	Name        string                    `json:"name"`
-	Label       string                    `json:"label"`
-	LabelPlural string                    `json:"labelPlural"`
-	KeyPrefix   string                    `json:"keyPrefix"`
-	Custom      bool                      `json:"custom"`
-	Createable  bool                      `json:"createable"`
-	Deletable   bool                      `json:"deletable"`
-	Updateable  bool                      `json:"updateable"`
-	Queryable   bool                      `json:"queryable"`
-	Fields      []SalesforceDescribeField `json:"fields"`
+	Name               string                        `json:"name"`
+	Label              string                        `json:"label"`
+	LabelPlural        string                        `json:"labelPlural"`
+	KeyPrefix          string                        `json:"keyPrefix"`
+	Custom             bool                          `json:"custom"`
+	Createable         bool                          `json:"createable"`
+	Deletable          bool                          `json:"deletable"`
+	Updateable         bool                          `json:"updateable"`
+	Queryable          bool                          `json:"queryable"`
+	Fields             []SalesforceDescribeField     `json:"fields"`
+	ChildRelationships []SalesforceChildRelationship `json:"childRelationships"`
+	RecordTypeInfos    []SalesforceRecordTypeInfo    `json:"recordTypeInfos"`
+}
+
+// DescribeSections selects which parts of a describe result are rendered
+type DescribeSections struct {
+	Fields             bool
+	FieldDetails       bool
+	ChildRelationships bool
+	RecordTypes        bool
+}
+
+// DefaultDescribeSections is the section list used when none is given
+const DefaultDescribeSections = "fields,child_relationships,record_types"
+
+// ParseDescribeSections parses a comma separated list of fields, field_details,
+// child_relationships, record_types or all
+func ParseDescribeSections(value string) (DescribeSections, error) {
+	var sections DescribeSections
+	for _, name := range splitList(value) {
+		switch strings.ToLower(name) {
+		case "fields":
+			sections.Fields = true
+		case "field_details":
+			sections.Fields = true
+			sections.FieldDetails = true
+		case "child_relationships", "relationships":
+			sections.ChildRelationships = true
+		case "record_types":
+			sections.RecordTypes = true
+		case "all":
+			sections = DescribeSections{Fields: true, FieldDetails: true, ChildRelationships: true, RecordTypes: true}
+		default:
+			return sections, fmt.Errorf("unknown describe section %q (supported: fields, field_details, child_relationships, record_types, all)", name)
+		}
+	}
+	return sections, nil
 }
 
 // SalesforceClient handles Salesforce API operations
 type SalesforceClient struct {
-	config *Config
-	auth   *SalesforceAuth
+	config     *OrgConfig
+	auth       *SalesforceAuth
+	issuedAt   time.Time
+	expiresAt  time.Time
+	version    string
+	authMutex  sync.RWMutex
+	httpClient *http.Client
+	// describeCache is shared by every client of the process and may be nil
+	describeCache *DescribeCache
+	// bulkDir confines the local files of the bulk tools; DefaultBulkDir is used when empty
+	bulkDir string
+	// reauth is called with the rejected access token when Salesforce reports an invalid session
+	reauth func(staleToken string) error
 }
 
-// NewSalesforceClient creates a new Salesforce client
-func NewSalesforceClient(config *Config) *SalesforceClient {
+// NewSalesforceClient creates a new Salesforce client for an org profile
+func NewSalesforceClient(config *OrgConfig) *SalesforceClient {
 	return &SalesforceClient{
-		config: config,
+		config:     config,
+		httpClient: &http.Client{Timeout: config.SalesforceTimeout},
 	}
 }
 
-// ValidateConfig checks if Salesforce configuration is complete
+// log returns the logger for this client's org
+func (sf *SalesforceClient) log() *slog.Logger {
+	return Logger().With("org", sf.config.Name)
+}
+
+// ValidateConfig checks if Salesforce configuration is complete for the selected auth flow
 func (sf *SalesforceClient) ValidateConfig() error {
-	if sf.config.SalesforceClientID == "" {
-		return fmt.Errorf("SALESFORCE_CLIENT_ID is required")
-	}
-	if sf.config.SalesforceClientSecret == "" {
-		return fmt.Errorf("SALESFORCE_CLIENT_SECRET is required")
-	}
-	if sf.config.SalesforceUsername == "" {
-		return fmt.Errorf("SALESFORCE_USERNAME is required")
-	}
-	if sf.config.SalesforcePassword == "" {
-		return fmt.Errorf("SALESFORCE_PASSWORD is required")
+	flow, err := GetAuthFlow(sf.config.AuthFlowName())
+	if err != nil {
+		return err
 	}
-	return nil
+	return flow.Validate(sf.config)
 }
 
-// Authenticate performs OAuth authentication with Salesforce
+// Authenticate performs OAuth authentication with Salesforce using the configured auth flow
 func (sf *SalesforceClient) Authenticate() error {
-	if err := sf.ValidateConfig(); err != nil {
+	flow, err := GetAuthFlow(sf.config.AuthFlowName())
+	if err != nil {
+		return err
+	}
+	if err := flow.Validate(sf.config); err != nil {
 		return err
 	}
 
 	// Prepare authentication request
-	data := url.Values{}
-	data.Set("grant_type", "password")
-	data.Set("client_id", sf.config.SalesforceClientID)
-	data.Set("client_secret", sf.config.SalesforceClientSecret)
-	data.Set("username", sf.config.SalesforceUsername)
-	data.Set("password", sf.config.SalesforcePassword+sf.config.SalesforceSecurityToken)
-
-	// Make authentication request
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.PostForm(sf.config.SalesforceURL+"/services/oauth2/token", data)
+	endpoint, data, err := flow.TokenRequest(sf.config)
 	if err != nil {
-		return fmt.Errorf("failed to make authentication request: %v", err)
+		return fmt.Errorf("failed to prepare authentication request: %v", err)
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	auth, err := requestToken(endpoint, data)
 	if err != nil {
-		return fmt.Errorf("failed to read authentication response: %v", err)
+		return err
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		var errorResp SalesforceErrorResponse
-		if err := json.Unmarshal(body, &errorResp); err == nil {
-			return fmt.Errorf("authentication failed: %s - %s", errorResp.Error, errorResp.ErrorDescription)
-		}
-		return fmt.Errorf("authentication failed with status %d: %s", resp.StatusCode, string(body))
+	// Work out the token lifetime from introspection or issued_at and the session timeout
+	issuedAt := parseIssuedAt(auth.IssuedAt)
+	expiresAt, err := introspectExpiry(sf.config, auth)
+	if err != nil || expiresAt.IsZero() {
+		expiresAt = issuedAt.Add(sf.config.SalesforceSessionTimeout)
 	}
 
-	var auth SalesforceAuth
-	if err := json.Unmarshal(body, &auth); err != nil {
-		return fmt.Errorf("failed to parse authentication response: %v", err)
+	sf.authMutex.Lock()
+	sf.auth = auth
+	sf.issuedAt = issuedAt
+	sf.expiresAt = expiresAt
+	resolved := sf.version != ""
+	sf.authMutex.Unlock()
+
+	// Resolve the API version once, after the first authentication
+	if !resolved {
+		version, err := sf.resolveAPIVersion(context.WithValue(context.Background(), authenticatingKey{}, true))
+		if err != nil {
+			return err
+		}
+		sf.authMutex.Lock()
+		sf.version = version
+		sf.authMutex.Unlock()
 	}
 
-	sf.auth = &auth
+	sf.log().Info("authenticated", "auth_flow", sf.config.AuthFlowName(), "instance_url", auth.InstanceURL,
+		"api_version", sf.apiVersion(), "expires_at", expiresAt.Format(time.RFC3339))
 	return nil
 }
 
-// Query executes a SOQL query against Salesforce
-func (sf *SalesforceClient) Query(query string) (*SalesforceQueryResponse, error) {
+// session returns the current authentication details
+func (sf *SalesforceClient) session() (*SalesforceAuth, error) {
+	sf.authMutex.RLock()
+	defer sf.authMutex.RUnlock()
 	if sf.auth == nil {
 		return nil, fmt.Errorf("not authenticated, call Authenticate() first")
 	}
+	return sf.auth, nil
+}
 
-	// Prepare query URL
-	queryURL := fmt.Sprintf("%s/services/data/v57.0/query", sf.auth.InstanceURL)
+// ExpiresAt returns when the current access token is expected to expire
+func (sf *SalesforceClient) ExpiresAt() time.Time {
+	sf.authMutex.RLock()
+	defer sf.authMutex.RUnlock()
+	return sf.expiresAt
+}
 
-	// URL encode the query
-	params := url.Values{}
-	params.Add("q", query)
-	fullURL := fmt.Sprintf("%s?%s", queryURL, params.Encode())
+// needsRefresh reports whether the client has no token or the token has used up
+// more than 90% of its lifetime, leaving a buffer to avoid expiry during requests
+func (sf *SalesforceClient) needsRefresh() bool {
+	sf.authMutex.RLock()
+	defer sf.authMutex.RUnlock()
+	if sf.auth == nil {
+		return true
+	}
+	lifetime := sf.expiresAt.Sub(sf.issuedAt)
+	return time.Now().After(sf.expiresAt.Add(-lifetime / 10))
+}
 
-	// Create HTTP request
-	req, err := http.NewRequest("GET", fullURL, nil)
+// authenticatingKey marks the requests Authenticate makes with the token it just obtained.
+// A session rejected then is not re-authenticated: the reauth hook would wait for the
+// authentication that is making the request.
+type authenticatingKey struct{}
+
+// do sends an authenticated request. When Salesforce rejects the session with HTTP 401 or
+// INVALID_SESSION_ID, it re-authenticates once and retries the request with the new token.
+func (sf *SalesforceClient) do(req *http.Request) (*http.Response, error) {
+	auth, err := sf.session()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %v", err)
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", auth.AccessToken))
+	resp, err := sf.httpClient.Do(req)
+	if err != nil || sf.reauth == nil || req.Context().Value(authenticatingKey{}) != nil || !isInvalidSession(resp) {
+		sf.logWarnings(req, resp)
+		return resp, err
+	}
+	// Requests whose body cannot be replayed are returned as is
+	if req.Body != nil && req.GetBody == nil {
+		sf.logWarnings(req, resp)
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	sf.log().WarnContext(req.Context(), "session rejected, re-authenticating",
+		"method", req.Method, "path", req.URL.Path, "status", resp.StatusCode)
+	if err := sf.reauth(auth.AccessToken); err != nil {
+		return nil, fmt.Errorf("session expired and re-authentication failed: %v", err)
+	}
+
+	retry := req.Clone(req.Context())
+	if req.Body != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, fmt.Errorf("failed to replay request body: %v", err)
+		}
+	}
+	if auth, err = sf.session(); err != nil {
+		return nil, err
 	}
+	retry.Header.Set("Authorization", fmt.Sprintf("Bearer %s", auth.AccessToken))
+	sf.log().InfoContext(req.Context(), "retrying request with new session", "method", req.Method, "path", req.URL.Path)
+	resp, err = sf.httpClient.Do(retry)
+	sf.logWarnings(retry, resp)
+	return resp, err
+}
 
-	// Set authorization header
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", sf.auth.AccessToken))
+// logWarnings logs the Warning headers Salesforce adds to responses, e.g. for deprecated API versions
+func (sf *SalesforceClient) logWarnings(req *http.Request, resp *http.Response) {
+	if resp == nil {
+		return
+	}
+	for _, warning := range resp.Header.Values("Warning") {
+		sf.log().WarnContext(req.Context(), "Salesforce warning", "method", req.Method, "path", req.URL.Path, "warning", warning)
+	}
+}
+
+// isInvalidSession reports whether a response rejects the session. Error bodies are
+// buffered so callers can still read them.
+func isInvalidSession(resp *http.Response) bool {
+	if resp.StatusCode == http.StatusUnauthorized {
+		return true
+	}
+	if resp.StatusCode < http.StatusBadRequest {
+		return false
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return err == nil && bytes.Contains(body, []byte("INVALID_SESSION_ID"))
+}
+
+// getJSON performs an authenticated GET request and decodes the JSON response into result
+func (sf *SalesforceClient) getJSON(ctx context.Context, requestURL, operation string, result interface{}) error {
+	return sf.requestJSON(ctx, "GET", requestURL, operation, nil, result)
+}
+
+// requestJSON performs an authenticated request with an optional JSON payload and decodes
+// the JSON response into result. Empty responses such as 204 No Content leave result untouched.
+func (sf *SalesforceClient) requestJSON(ctx context.Context, method, requestURL, operation string, payload, result interface{}) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s request: %v", operation, err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	// Create HTTP request
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
 	req.Header.Set("Content-Type", "application/json")
 
 	// Make request
-	client := &http.Client{Timeout: 60 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := sf.do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute %s: %v", operation, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read %s response: %v", operation, err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return apiError(operation, resp.StatusCode, respBody)
+	}
+
+	if result == nil || len(bytes.TrimSpace(respBody)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, result); err != nil {
+		return fmt.Errorf("failed to parse %s response: %v", operation, err)
+	}
+	return nil
+}
+
+// apiError builds an error from a Salesforce REST error response, which is either a
+// list of errors or a single error wrapper
+func apiError(operation string, statusCode int, body []byte) error {
+	var errorList []SalesforceError
+	if err := json.Unmarshal(body, &errorList); err == nil && len(errorList) > 0 {
+		return fmt.Errorf("%s failed: %s - %s", operation, errorList[0].ErrorCode, errorList[0].Message)
+	}
+	var errorResp SalesforceErrorResponse
+	if err := json.Unmarshal(body, &errorResp); err == nil && len(errorResp.Errors) > 0 {
+		return fmt.Errorf("%s failed: %s - %s", operation, errorResp.Errors[0].ErrorCode, errorResp.Errors[0].Message)
+	}
+	return fmt.Errorf("%s failed with status %d: %s", operation, statusCode, string(body))
+}
+
+// requestToken posts an OAuth token request and parses the token response
+func requestToken(endpoint string, data url.Values) (*SalesforceAuth, error) {
+	// Make authentication request
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.PostForm(endpoint, data)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute query: %v", err)
+		return nil, fmt.Errorf("failed to make authentication request: %v", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read query response: %v", err)
+		return nil, fmt.Errorf("failed to read authentication response: %v", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
 		var errorResp SalesforceErrorResponse
-		if err := json.Unmarshal(body, &errorResp); err == nil && len(errorResp.Errors) > 0 {
-			return nil, fmt.Errorf("query failed: %s - %s", errorResp.Errors[0].ErrorCode, errorResp.Errors[0].Message)
+		if err := json.Unmarshal(body, &errorResp); err == nil {
+			return nil, fmt.Errorf("authentication failed: %s - %s", errorResp.Error, errorResp.ErrorDescription)
 		}
-		return nil, fmt.Errorf("query failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("authentication failed with status %d: %s", resp.StatusCode, string(body))
 	}
 
-	var result SalesforceQueryResponse
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to parse query response: %v", err)
+	var auth SalesforceAuth
+	if err := json.Unmarshal(body, &auth); err != nil {
+		return nil, fmt.Errorf("failed to parse authentication response: %v", err)
 	}
 
-	return &result, nil
+	return &auth, nil
 }
 
-// Describe gets the metadata for a Salesforce object
-func (sf *SalesforceClient) Describe(objectType string) (*SalesforceDescribeResponse, error) {
-	if sf.auth == nil {
-		return nil, fmt.Errorf("not authenticated, call Authenticate() first")
+// Query executes a SOQL query against Salesforce, following nextRecordsUrl until
+// maxRecords records have been fetched or the query is done. A maxRecords of 0 or
+// less fetches every page.
+func (sf *SalesforceClient) Query(ctx context.Context, query string, maxRecords int) (*SalesforceQueryResponse, error) {
+	return sf.runQuery(ctx, "query", query, maxRecords)
+}
+
+// QueryAll executes a SOQL query through /queryAll, which also returns soft-deleted
+// records in the recycle bin and archived activities. Pagination works as in Query.
+func (sf *SalesforceClient) QueryAll(ctx context.Context, query string, maxRecords int) (*SalesforceQueryResponse, error) {
+	return sf.runQuery(ctx, "queryAll", query, maxRecords)
+}
+
+// runQuery executes a SOQL query against the query or queryAll resource and follows
+// the query locators until maxRecords or the end of the results
+func (sf *SalesforceClient) runQuery(ctx context.Context, resource, query string, maxRecords int) (*SalesforceQueryResponse, error) {
+	query, policyNotes, err := sf.applyQueryPolicy(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	// URL encode the query
+	params := url.Values{}
+	params.Add("q", query)
+	pageURL, err := sf.dataURL(resource + "?" + params.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SalesforceQueryResponse{PolicyNotes: policyNotes}
+	for {
+		page, err := sf.fetchQueryPage(ctx, pageURL)
+		if err != nil {
+			return nil, err
+		}
+
+		result.PagesFetched++
+		if result.PagesFetched == 1 {
+			result.TotalSize = page.TotalSize
+		}
+		result.Records = append(result.Records, page.Records...)
+		result.Done = page.Done
+		result.NextRecordsURL = page.NextRecordsURL
+		sf.log().DebugContext(ctx, "fetched query page", "resource", resource, "page", result.PagesFetched,
+			"records", len(result.Records), "total_size", result.TotalSize)
+		total := result.TotalSize
+		if maxRecords > 0 && maxRecords < total {
+			total = maxRecords
+		}
+		reportProgress(ctx, float64(min(len(result.Records), total)), float64(total),
+			fmt.Sprintf("Fetched %d of %d records", min(len(result.Records), total), total))
+
+		if maxRecords > 0 && len(result.Records) >= maxRecords {
+			if len(result.Records) > maxRecords {
+				// The locator no longer lines up with the trimmed records
+				result.Records = result.Records[:maxRecords]
+				result.NextRecordsURL = ""
+				result.MaxRecordsReached = true
+			} else if !page.Done {
+				result.MaxRecordsReached = true
+			}
+			break
+		}
+
+		if page.Done || page.NextRecordsURL == "" {
+			break
+		}
+		// Query locators are absolute paths that already carry the API version
+		auth, err := sf.session()
+		if err != nil {
+			return nil, err
+		}
+		pageURL = auth.InstanceURL + page.NextRecordsURL
+	}
+
+	return result, nil
+}
+
+// fetchQueryPage retrieves a single batch of query results from a query or query locator URL
+func (sf *SalesforceClient) fetchQueryPage(ctx context.Context, pageURL string) (*SalesforceQueryResponse, error) {
+	var result SalesforceQueryResponse
+	if err := sf.getJSON(ctx, pageURL, "query", &result); err != nil {
+		return nil, err
 	}
+	return &result, nil
+}
 
+// Describe gets the metadata for a Salesforce object. Cached results are revalidated with
+// If-Modified-Since and reused when Salesforce answers 304 Not Modified; refresh skips the cache.
+func (sf *SalesforceClient) Describe(ctx context.Context, objectType string, refresh bool) (*SalesforceDescribeResponse, error) {
 	// Prepare describe URL
-	describeURL := fmt.Sprintf("%s/services/data/v57.0/sobjects/%s/describe", sf.auth.InstanceURL, objectType)
+	describeURL, err := sf.dataURL(fmt.Sprintf("sobjects/%s/describe", url.PathEscape(objectType)))
+	if err != nil {
+		return nil, err
+	}
 
 	// Create HTTP request
-	req, err := http.NewRequest("GET", describeURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", describeURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %v", err)
 	}
-
-	// Set authorization header
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", sf.auth.AccessToken))
 	req.Header.Set("Content-Type", "application/json")
 
+	// Revalidate a cached describe instead of downloading it again
+	var cached *describeCacheEntry
+	cacheKey := describeCacheKey(sf.config.Name, sf.apiVersion(), objectType)
+	if sf.describeCache != nil {
+		if refresh {
+			sf.describeCache.record(func(stats *DescribeCacheStats) { stats.Refreshes++ })
+		} else if cached = sf.describeCache.get(cacheKey); cached != nil {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
 	// Make request
-	client := &http.Client{Timeout: 60 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := sf.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute describe: %v", err)
 	}
@@ -228,12 +570,28 @@ func (sf *SalesforceClient) Describe(objectType string) (*SalesforceDescribeResp
 		return nil, fmt.Errorf("failed to read describe response: %v", err)
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		var errorResp SalesforceErrorResponse
-		if err := json.Unmarshal(body, &errorResp); err == nil && len(errorResp.Errors) > 0 {
-			return nil, fmt.Errorf("describe failed: %s - %s", errorResp.Errors[0].ErrorCode, errorResp.Errors[0].Message)
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		sf.log().DebugContext(ctx, "describe cache hit", "object", objectType)
+		sf.describeCache.record(func(stats *DescribeCacheStats) { stats.Hits++ })
+		body = cached.Body
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, apiError("describe", resp.StatusCode, body)
+	} else if sf.describeCache != nil {
+		sf.describeCache.record(func(stats *DescribeCacheStats) {
+			if cached != nil {
+				stats.Changed++
+			} else if !refresh {
+				stats.Misses++
+			}
+		})
+		lastModified := resp.Header.Get("Last-Modified")
+		if lastModified == "" {
+			lastModified = time.Now().UTC().Format(http.TimeFormat)
+		}
+		// A failed disk write only costs a future cache miss
+		if err := sf.describeCache.put(cacheKey, &describeCacheEntry{LastModified: lastModified, Body: body}); err != nil {
+			sf.log().WarnContext(ctx, "failed to write describe cache", "object", objectType, "error", err)
 		}
-		return nil, fmt.Errorf("describe failed with status %d: %s", resp.StatusCode, string(body))
 	}
 
 	var result SalesforceDescribeResponse
@@ -246,13 +604,25 @@ func (sf *SalesforceClient) Describe(objectType string) (*SalesforceDescribeResp
 
 // FormatAsTable formats query results as a simple table
 func FormatAsTable(result *SalesforceQueryResponse) string {
+	var warnings bytes.Buffer
+	for _, warning := range result.Warnings {
+		warnings.WriteString(fmt.Sprintf("Warning: %s\n", warning))
+	}
 	if result.TotalSize == 0 {
-		return "No records found."
+		return warnings.String() + "No records found."
 	}
 
 	var buffer bytes.Buffer
+	buffer.WriteString(warnings.String())
 	buffer.WriteString(fmt.Sprintf("Total Records: %d\n", result.TotalSize))
 	buffer.WriteString(fmt.Sprintf("Records Returned: %d\n", len(result.Records)))
+	buffer.WriteString(fmt.Sprintf("Pages Fetched: %d\n", result.PagesFetched))
+	if result.MaxRecordsReached {
+		buffer.WriteString("Stopped at max_records; more records are available.\n")
+	}
+	for _, note := range result.PolicyNotes {
+		buffer.WriteString(fmt.Sprintf("Query policy: %s\n", note))
+	}
 	buffer.WriteString(strings.Repeat("-", 50) + "\n")
 
 	for i, record := range result.Records {
@@ -276,8 +646,8 @@ func FormatAsJSON(result *SalesforceQueryResponse) string {
 	return string(jsonBytes)
 }
 
-// FormatDescribeAsTable formats describe results as a readable table
-func FormatDescribeAsTable(result *SalesforceDescribeResponse) string {
+// FormatDescribeAsTable formats the selected sections of describe results as readable tables
+func FormatDescribeAsTable(result *SalesforceDescribeResponse, sections DescribeSections) string {
 	var buffer bytes.Buffer
 
 	// Object information
@@ -287,31 +657,116 @@ func FormatDescribeAsTable(result *SalesforceDescribeResponse) string {
 	buffer.WriteString(fmt.Sprintf("Custom: %t\n", result.Custom))
 	buffer.WriteString(fmt.Sprintf("Permissions: Create=%t, Update=%t, Delete=%t, Query=%t\n",
 		result.Createable, result.Updateable, result.Deletable, result.Queryable))
-	buffer.WriteString(strings.Repeat("=", 80) + "\n")
-	buffer.WriteString("FIELDS:\n")
-	buffer.WriteString(strings.Repeat("=", 80) + "\n")
 
-	// Field headers
-	buffer.WriteString(fmt.Sprintf("%-30s %-20s %-15s %-10s %-8s %-8s\n",
-		"Field Name", "Label", "Type", "Length", "Required", "Unique"))
-	buffer.WriteString(strings.Repeat("-", 80) + "\n")
+	if sections.Fields {
+		buffer.WriteString(strings.Repeat("=", 80) + "\n")
+		buffer.WriteString("FIELDS:\n")
+		buffer.WriteString(strings.Repeat("=", 80) + "\n")
+
+		// Field headers
+		buffer.WriteString(fmt.Sprintf("%-30s %-20s %-15s %-10s %-8s %-8s %-8s %-25s %s\n",
+			"Field Name", "Label", "Type", "Length", "Required", "Unique", "Nillable", "Relationship", "Reference To"))
+		buffer.WriteString(strings.Repeat("-", 80) + "\n")
+
+		// Field details
+		for _, field := range result.Fields {
+			length := ""
+			if field.Length > 0 {
+				length = fmt.Sprintf("%d", field.Length)
+			}
 
-	// Field details
-	for _, field := range result.Fields {
-		length := ""
-		if field.Length > 0 {
-			length = fmt.Sprintf("%d", field.Length)
+			buffer.WriteString(fmt.Sprintf("%-30s %-20s %-15s %-10s %-8t %-8t %-8t %-25s %s\n",
+				field.Name, field.Label, field.Type, length, field.Required, field.Unique,
+				field.Nillable, field.RelationshipName, strings.Join(field.ReferenceTo, ", ")))
 		}
+	}
+
+	if sections.FieldDetails {
+		buffer.WriteString(strings.Repeat("=", 80) + "\n")
+		buffer.WriteString("FIELD DETAILS:\n")
+		buffer.WriteString(strings.Repeat("=", 80) + "\n")
 
-		buffer.WriteString(fmt.Sprintf("%-30s %-20s %-15s %-10s %-8t %-8t\n",
-			field.Name, field.Label, field.Type, length, field.Required, field.Unique))
+		buffer.WriteString(fmt.Sprintf("%-30s %-10s %-10s %-10s %-8s %-9s %-9s %-9s %s\n",
+			"Field Name", "Calculated", "ExternalId", "Filterable", "Sortable", "Groupable", "Precision", "Scale", "Formula"))
+		buffer.WriteString(strings.Repeat("-", 80) + "\n")
+
+		for _, field := range result.Fields {
+			buffer.WriteString(fmt.Sprintf("%-30s %-10t %-10t %-10t %-8t %-9t %-9d %-9d %s\n",
+				field.Name, field.Calculated, field.ExternalID, field.Filterable, field.Sortable,
+				field.Groupable, field.Precision, field.Scale, field.CalculatedFormula))
+		}
+	}
+
+	if sections.ChildRelationships {
+		buffer.WriteString(strings.Repeat("=", 80) + "\n")
+		buffer.WriteString("CHILD RELATIONSHIPS:\n")
+		buffer.WriteString(strings.Repeat("=", 80) + "\n")
+
+		buffer.WriteString(fmt.Sprintf("%-35s %-35s %-30s %-8s\n",
+			"Relationship Name", "Child Object", "Field", "Cascade"))
+		buffer.WriteString(strings.Repeat("-", 80) + "\n")
+
+		for _, rel := range result.ChildRelationships {
+			// Relationships without a name cannot be used in subqueries
+			if rel.RelationshipName == "" {
+				continue
+			}
+			buffer.WriteString(fmt.Sprintf("%-35s %-35s %-30s %-8t\n",
+				rel.RelationshipName, rel.ChildSObject, rel.Field, rel.CascadeDelete))
+		}
+	}
+
+	if sections.RecordTypes {
+		buffer.WriteString(strings.Repeat("=", 80) + "\n")
+		buffer.WriteString("RECORD TYPES:\n")
+		buffer.WriteString(strings.Repeat("=", 80) + "\n")
+
+		buffer.WriteString(fmt.Sprintf("%-30s %-30s %-20s %-8s %-10s %-8s\n",
+			"Name", "Developer Name", "Record Type Id", "Active", "Available", "Default"))
+		buffer.WriteString(strings.Repeat("-", 80) + "\n")
+
+		for _, rt := range result.RecordTypeInfos {
+			buffer.WriteString(fmt.Sprintf("%-30s %-30s %-20s %-8t %-10t %-8t\n",
+				rt.Name, rt.DeveloperName, rt.RecordTypeID, rt.Active, rt.Available, rt.DefaultRecordTypeMapping))
+		}
 	}
 
 	return buffer.String()
 }
 
-// FormatDescribeAsJSON formats describe results as JSON
-func FormatDescribeAsJSON(result *SalesforceDescribeResponse) string {
-	jsonBytes, _ := json.MarshalIndent(result, "", "  ")
+// describeFieldDetailKeys are the field properties only included with the field_details section
+var describeFieldDetailKeys = []string{
+	"defaultedOnCreate", "calculated", "calculatedFormula", "externalId", "idLookup",
+	"filterable", "sortable", "groupable", "precision", "scale", "digits", "inlineHelpText",
+}
+
+// FormatDescribeAsJSON formats the selected sections of describe results as JSON
+func FormatDescribeAsJSON(result *SalesforceDescribeResponse, sections DescribeSections) string {
+	// Round trip through a map so unselected sections can be dropped
+	var output map[string]interface{}
+	jsonBytes, _ := json.Marshal(result)
+	_ = json.Unmarshal(jsonBytes, &output)
+
+	if !sections.Fields {
+		delete(output, "fields")
+	} else if !sections.FieldDetails {
+		if fields, ok := output["fields"].([]interface{}); ok {
+			for _, field := range fields {
+				if fieldMap, ok := field.(map[string]interface{}); ok {
+					for _, key := range describeFieldDetailKeys {
+						delete(fieldMap, key)
+					}
+				}
+			}
+		}
+	}
+	if !sections.ChildRelationships {
+		delete(output, "childRelationships")
+	}
+	if !sections.RecordTypes {
+		delete(output, "recordTypeInfos")
+	}
+
+	jsonBytes, _ = json.MarshalIndent(output, "", "  ")
 	return string(jsonBytes)
 }
@@ -0,0 +1,144 @@
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newQueryTestClient returns a client for a test server that serves a query of total
+// records in pages of pageSize through query locators, counting the pages served
+func newQueryTestClient(t *testing.T, total, pageSize int) (*SalesforceClient, *int) {
+	t.Helper()
+	const prefix = "/services/data/v62.0/query"
+	pagesServed := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The first page is the query itself; later pages are read from the locator
+		start := 0
+		if r.URL.Path != prefix {
+			if _, err := fmt.Sscanf(r.URL.Path, prefix+"/01gxx0000000001-%d", &start); err != nil {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`[{"message":"invalid query locator","errorCode":"INVALID_QUERY_LOCATOR"}]`))
+				return
+			}
+		}
+		pagesServed++
+
+		page := SalesforceQueryResponse{TotalSize: total, Done: true, Records: []interface{}{}}
+		end := min(start+pageSize, total)
+		for i := start; i < end; i++ {
+			page.Records = append(page.Records, map[string]interface{}{"Id": fmt.Sprintf("001%012d", i)})
+		}
+		if end < total {
+			page.Done = false
+			page.NextRecordsURL = fmt.Sprintf("%s/01gxx0000000001-%d", prefix, end)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(page)
+	}))
+	t.Cleanup(server.Close)
+
+	sf := NewSalesforceClient(&OrgConfig{Name: "test"})
+	sf.auth = &SalesforceAuth{AccessToken: "token", InstanceURL: server.URL}
+	sf.version = "v62.0"
+	return sf, &pagesServed
+}
+
+func TestQueryPagination(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxRecords int
+		records    int
+		pages      int
+		done       bool
+		reached    bool
+		locator    bool
+	}{
+		{name: "every page", maxRecords: 0, records: 8, pages: 3, done: true},
+		{name: "max records at the end", maxRecords: 8, records: 8, pages: 3, done: true},
+		{name: "max records on a page boundary", maxRecords: 6, records: 6, pages: 2, reached: true, locator: true},
+		{name: "max records within a page", maxRecords: 4, records: 4, pages: 2, reached: true},
+		{name: "max records above the total", maxRecords: 100, records: 8, pages: 3, done: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sf, pagesServed := newQueryTestClient(t, 8, 3)
+
+			result, err := sf.Query(context.Background(), "SELECT Id FROM Account", test.maxRecords)
+			if err != nil {
+				t.Fatalf("Query failed: %v", err)
+			}
+			if len(result.Records) != test.records || result.PagesFetched != test.pages || *pagesServed != test.pages {
+				t.Errorf("records, pages fetched, pages served = %d, %d, %d, want %d, %d, %d",
+					len(result.Records), result.PagesFetched, *pagesServed, test.records, test.pages, test.pages)
+			}
+			if result.TotalSize != 8 {
+				t.Errorf("TotalSize = %d, want 8", result.TotalSize)
+			}
+			if result.Done != test.done || result.MaxRecordsReached != test.reached {
+				t.Errorf("Done, MaxRecordsReached = %t, %t, want %t, %t", result.Done, result.MaxRecordsReached, test.done, test.reached)
+			}
+			if (result.NextRecordsURL != "") != test.locator {
+				t.Errorf("NextRecordsURL = %q, want a locator: %t", result.NextRecordsURL, test.locator)
+			}
+			for i, record := range result.Records {
+				if id := record.(map[string]interface{})["Id"]; id != fmt.Sprintf("001%012d", i) {
+					t.Errorf("record %d has Id %v", i, id)
+				}
+			}
+		})
+	}
+}
+
+func TestQueryProgressAndCancellation(t *testing.T) {
+	blocked := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/services/data/v62.0/query" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"totalSize":8,"done":false,"nextRecordsUrl":"/services/data/v62.0/query/01gxx0000000001-3","records":[{"Id":"1"},{"Id":"2"},{"Id":"3"}]}`))
+			return
+		}
+		// Hold the next page until the client gives up
+		close(blocked)
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+	sf := NewSalesforceClient(&OrgConfig{Name: "test"})
+	sf.auth = &SalesforceAuth{AccessToken: "token", InstanceURL: server.URL}
+	sf.version = "v62.0"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var updates []string
+	ctx = WithProgress(ctx, func(progress, total float64, message string) {
+		updates = append(updates, fmt.Sprintf("%g/%g %s", progress, total, message))
+	})
+	go func() {
+		<-blocked
+		cancel()
+	}()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := sf.Query(ctx, "SELECT Id FROM Account", 0)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "context canceled") {
+			t.Errorf("got %v, want a cancellation error", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Query did not stop when its context was cancelled")
+	}
+	if want := []string{"3/8 Fetched 3 of 8 records"}; !reflect.DeepEqual(updates, want) {
+		t.Errorf("progress = %q, want %q", updates, want)
+	}
+}
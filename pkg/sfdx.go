@@ -0,0 +1,230 @@
+package pkg
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// sfdxDefaultClientID is the connected app the Salesforce CLI uses when none is given
+const sfdxDefaultClientID = "PlatformCLI"
+
+// SfdxAuthInfo holds the fields soql-mcp needs from a Salesforce CLI auth file
+type SfdxAuthInfo struct {
+	Username     string `json:"username"`
+	OrgID        string `json:"orgId"`
+	InstanceURL  string `json:"instanceUrl"`
+	LoginURL     string `json:"loginUrl"`
+	ClientID     string `json:"clientId"`
+	ClientSecret string `json:"clientSecret"`
+	RefreshToken string `json:"refreshToken"`
+}
+
+// sfdxFlow reuses an org the Salesforce CLI (sf/sfdx) is already logged in to
+type sfdxFlow struct{}
+
+func (sfdxFlow) Validate(config *OrgConfig) error {
+	if config.SalesforceOrgAlias == "" {
+		return fmt.Errorf("SALESFORCE_ORG_ALIAS is required")
+	}
+	info, err := LoadSfdxAuthInfo(config.SalesforceOrgAlias)
+	if err != nil {
+		return err
+	}
+	if info.RefreshToken == "" {
+		return fmt.Errorf("Salesforce CLI org %s has no refresh token, log in again with `sf org login web`", config.SalesforceOrgAlias)
+	}
+	return nil
+}
+
+func (sfdxFlow) TokenRequest(config *OrgConfig) (string, url.Values, error) {
+	info, err := LoadSfdxAuthInfo(config.SalesforceOrgAlias)
+	if err != nil {
+		return "", nil, err
+	}
+
+	refreshToken, err := decryptSfdxValue(info.RefreshToken)
+	if err != nil {
+		return "", nil, err
+	}
+
+	clientID := info.ClientID
+	if clientID == "" {
+		clientID = sfdxDefaultClientID
+	}
+	loginURL := info.LoginURL
+	if loginURL == "" {
+		loginURL = info.InstanceURL
+	}
+
+	data := url.Values{}
+	data.Set("grant_type", "refresh_token")
+	data.Set("client_id", clientID)
+	if info.ClientSecret != "" {
+		clientSecret, err := decryptSfdxValue(info.ClientSecret)
+		if err != nil {
+			return "", nil, err
+		}
+		data.Set("client_secret", clientSecret)
+	}
+	data.Set("refresh_token", refreshToken)
+	return tokenURL(loginURL), data, nil
+}
+
+// sfdxDir returns the Salesforce CLI state directory
+func sfdxDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate home directory: %v", err)
+	}
+	return filepath.Join(home, ".sfdx"), nil
+}
+
+// LoadSfdxAuthInfo reads the Salesforce CLI auth file for an alias or username
+func LoadSfdxAuthInfo(aliasOrUsername string) (*SfdxAuthInfo, error) {
+	dir, err := sfdxDir()
+	if err != nil {
+		return nil, err
+	}
+
+	username, err := resolveSfdxAlias(dir, aliasOrUsername)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, username+".json"))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no Salesforce CLI auth file found for %s, log in with `sf org login web --alias %s`", aliasOrUsername, aliasOrUsername)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Salesforce CLI auth file: %v", err)
+	}
+
+	var info SfdxAuthInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, fmt.Errorf("failed to parse Salesforce CLI auth file: %v", err)
+	}
+	return &info, nil
+}
+
+// resolveSfdxAlias maps an alias to a username using the CLI alias file,
+// returning the input unchanged when it is not a known alias
+func resolveSfdxAlias(dir, aliasOrUsername string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "alias.json"))
+	if os.IsNotExist(err) {
+		return aliasOrUsername, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read Salesforce CLI alias file: %v", err)
+	}
+
+	var aliases struct {
+		Orgs map[string]string `json:"orgs"`
+	}
+	if err := json.Unmarshal(data, &aliases); err != nil {
+		return "", fmt.Errorf("failed to parse Salesforce CLI alias file: %v", err)
+	}
+	if username, ok := aliases.Orgs[aliasOrUsername]; ok {
+		return username, nil
+	}
+	return aliasOrUsername, nil
+}
+
+// decryptSfdxValue decrypts a secret the Salesforce CLI stored with AES-256-GCM.
+// Values are formatted as <iv><ciphertext hex>:<auth tag hex>; plain values are returned as is.
+func decryptSfdxValue(value string) (string, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 2 {
+		return value, nil
+	}
+
+	key, err := sfdxEncryptionKey()
+	if err != nil {
+		return "", err
+	}
+
+	// Older CLI versions use a 32 character key and a 12 character IV as raw bytes,
+	// newer ones hex encode a 32 byte key and a 12 byte IV
+	var keyBytes, iv []byte
+	var secret string
+	if len(key) == 64 {
+		if keyBytes, err = hex.DecodeString(key); err != nil {
+			return "", fmt.Errorf("invalid Salesforce CLI encryption key: %v", err)
+		}
+		if len(parts[0]) < 24 {
+			return "", fmt.Errorf("invalid encrypted Salesforce CLI value")
+		}
+		if iv, err = hex.DecodeString(parts[0][:24]); err != nil {
+			return "", fmt.Errorf("invalid encrypted Salesforce CLI value: %v", err)
+		}
+		secret = parts[0][24:]
+	} else {
+		keyBytes = []byte(key)
+		if len(parts[0]) < 12 {
+			return "", fmt.Errorf("invalid encrypted Salesforce CLI value")
+		}
+		iv = []byte(parts[0][:12])
+		secret = parts[0][12:]
+	}
+
+	ciphertext, err := hex.DecodeString(secret + parts[1])
+	if err != nil {
+		return "", fmt.Errorf("invalid encrypted Salesforce CLI value: %v", err)
+	}
+
+	block, err := aes.NewCipher(keyBytes)
+	if err != nil {
+		return "", fmt.Errorf("invalid Salesforce CLI encryption key: %v", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", fmt.Errorf("failed to initialise decryption: %v", err)
+	}
+	plaintext, err := gcm.Open(nil, iv, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt Salesforce CLI value: %v", err)
+	}
+	return string(plaintext), nil
+}
+
+// sfdxEncryptionKey returns the key the Salesforce CLI encrypts auth files with. It is
+// read from ~/.sfdx/key.json when the CLI uses its generic keychain, and from the login
+// keychain on macOS otherwise.
+func sfdxEncryptionKey() (string, error) {
+	dir, err := sfdxDir()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "key.json"))
+	if err == nil {
+		var keyFile struct {
+			Key string `json:"key"`
+		}
+		if err := json.Unmarshal(data, &keyFile); err != nil {
+			return "", fmt.Errorf("failed to parse Salesforce CLI key file: %v", err)
+		}
+		return keyFile.Key, nil
+	}
+	if !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read Salesforce CLI key file: %v", err)
+	}
+
+	if runtime.GOOS == "darwin" {
+		out, err := exec.Command("security", "find-generic-password", "-a", "local", "-s", "sfdx", "-w").Output()
+		if err != nil {
+			return "", fmt.Errorf("failed to read Salesforce CLI key from keychain: %v", err)
+		}
+		return strings.TrimSpace(string(out)), nil
+	}
+
+	return "", fmt.Errorf("Salesforce CLI encryption key not found, set SF_USE_GENERIC_UNIX_KEYCHAIN=true and log in again")
+}
@@ -0,0 +1,502 @@
+// Package soql parses SOQL SELECT statements and the RETURNING clause of SOSL searches
+// into an AST and prints them back as canonical SOQL.
+package soql
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Node is implemented by every AST node
+type Node interface {
+	// Pos returns the position of the node's first token
+	Pos() Position
+	// String returns the node as canonical SOQL
+	String() string
+}
+
+// Query is a SELECT statement, either the top level query, a parent-to-child subquery in the
+// select list or a semi-join subquery in a WHERE condition
+type Query struct {
+	Select  []SelectItem
+	From    *ObjectRef
+	Scope   string
+	Where   Condition
+	With    *WithClause
+	GroupBy *GroupBy
+	Having  Condition
+	OrderBy []*OrderItem
+	Limit   Value
+	Offset  Value
+	// For holds VIEW, REFERENCE or UPDATE
+	For []string
+	// Update holds TRACKING or VIEWSTAT
+	Update []string
+	// Subquery is true for parenthesized queries
+	Subquery bool
+
+	StartPos Position
+}
+
+// Pos implements Node
+func (q *Query) Pos() Position { return q.StartPos }
+
+// Search is a SOSL search. Only the RETURNING clause is parsed into nodes; the text
+// before and after it is kept as written.
+type Search struct {
+	// Prefix is the text before RETURNING, such as FIND {Acme} IN NAME FIELDS
+	Prefix string
+	// In is the upper case search group of the IN clause, such as NAME, and is empty
+	// without one
+	In string
+	// Returning holds the objects of the RETURNING clause and is empty without one
+	Returning []*ReturningObject
+	// Suffix is the text after the RETURNING clause, such as WITH DIVISION = 'Global' LIMIT 20
+	Suffix   string
+	StartPos Position
+}
+
+// Pos implements Node
+func (s *Search) Pos() Position { return s.StartPos }
+
+// ReturningObject is one object of a SOSL RETURNING clause, such as
+// Contact(Id, Name WHERE Email != null ORDER BY Name LIMIT 10). An object without a
+// field list returns record IDs only.
+type ReturningObject struct {
+	Object   *ObjectRef
+	Fields   []*SelectExpr
+	ListView string
+	Where    Condition
+	OrderBy  []*OrderItem
+	Limit    Value
+	Offset   Value
+}
+
+// Pos implements Node
+func (r *ReturningObject) Pos() Position { return r.Object.Pos() }
+
+// ObjectRef is the object (or, in a subquery, the child relationship) a query reads from
+type ObjectRef struct {
+	Name     string
+	Alias    string
+	StartPos Position
+}
+
+// Pos implements Node
+func (o *ObjectRef) Pos() Position { return o.StartPos }
+
+// SelectItem is an entry of the select list: *SelectExpr, *Query or *TypeOf
+type SelectItem interface {
+	Node
+	selectItem()
+}
+
+// SelectExpr is a field, function call or aggregate in the select list, with an optional alias
+type SelectExpr struct {
+	Expr  Expr
+	Alias string
+}
+
+// Pos implements Node
+func (s *SelectExpr) Pos() Position { return s.Expr.Pos() }
+
+// TypeOf selects different fields depending on the type of a polymorphic relationship
+type TypeOf struct {
+	Relationship    string
+	Whens           []*TypeOfWhen
+	Else            []*FieldRef
+	StartPos        Position
+	RelationshipPos Position
+}
+
+// Pos implements Node
+func (t *TypeOf) Pos() Position { return t.StartPos }
+
+// TypeOfWhen is one WHEN <type> THEN <fields> branch of a TYPEOF expression
+type TypeOfWhen struct {
+	Type     string
+	Fields   []*FieldRef
+	StartPos Position
+	TypePos  Position
+}
+
+// Pos implements Node
+func (w *TypeOfWhen) Pos() Position { return w.StartPos }
+
+func (*SelectExpr) selectItem() {}
+func (*Query) selectItem()      {}
+func (*TypeOf) selectItem()     {}
+
+// Expr is a field reference or function call
+type Expr interface {
+	Node
+	expr()
+}
+
+// FieldRef is a field or relationship path such as Name or Account.Owner.Name
+type FieldRef struct {
+	Path []string
+	// PathPos holds the position of each element of Path
+	PathPos  []Position
+	StartPos Position
+}
+
+// Pos implements Node
+func (f *FieldRef) Pos() Position { return f.StartPos }
+
+// Name returns the dotted path
+func (f *FieldRef) Name() string { return strings.Join(f.Path, ".") }
+
+// FunctionCall is an aggregate or other function such as COUNT(Id), toLabel(Status),
+// CALENDAR_YEAR(CreatedDate) or FIELDS(STANDARD)
+type FunctionCall struct {
+	Name     string
+	Args     []Node
+	StartPos Position
+}
+
+// Pos implements Node
+func (f *FunctionCall) Pos() Position { return f.StartPos }
+
+// IsAggregate reports whether the function is an aggregate function
+func (f *FunctionCall) IsAggregate() bool {
+	switch strings.ToUpper(f.Name) {
+	case "AVG", "COUNT", "COUNT_DISTINCT", "MIN", "MAX", "SUM":
+		return true
+	}
+	return false
+}
+
+func (*FieldRef) expr()     {}
+func (*FunctionCall) expr() {}
+
+// WithClause is a WITH filter such as SECURITY_ENFORCED, USER_MODE or DATA CATEGORY
+type WithClause struct {
+	// Kind is SECURITY_ENFORCED, USER_MODE, SYSTEM_MODE or DATA CATEGORY
+	Kind string
+	// DataCategories holds the DATA CATEGORY filters, joined with AND
+	DataCategories []*DataCategoryFilter
+	StartPos       Position
+}
+
+// Pos implements Node
+func (w *WithClause) Pos() Position { return w.StartPos }
+
+// DataCategoryFilter is one <group> AT|ABOVE|BELOW|ABOVE_OR_BELOW <categories> filter
+type DataCategoryFilter struct {
+	Group      string
+	Selector   string
+	Categories []string
+	StartPos   Position
+}
+
+// Pos implements Node
+func (d *DataCategoryFilter) Pos() Position { return d.StartPos }
+
+// GroupBy is a GROUP BY clause; Kind is empty, ROLLUP or CUBE
+type GroupBy struct {
+	Kind     string
+	Exprs    []Expr
+	StartPos Position
+}
+
+// Pos implements Node
+func (g *GroupBy) Pos() Position { return g.StartPos }
+
+// OrderItem is one ORDER BY entry
+type OrderItem struct {
+	Expr Expr
+	// Direction is ASC, DESC or empty
+	Direction string
+	// Nulls is FIRST, LAST or empty
+	Nulls string
+}
+
+// Pos implements Node
+func (o *OrderItem) Pos() Position { return o.Expr.Pos() }
+
+// Condition is a WHERE or HAVING condition: *Comparison, *LogicalCondition or *NotCondition
+type Condition interface {
+	Node
+	condition()
+}
+
+// Comparison compares a field or function with a value, e.g. Name LIKE 'A%' or
+// Id IN (SELECT AccountId FROM Contact)
+type Comparison struct {
+	Left Expr
+	// Operator is =, !=, <, <=, >, >=, LIKE, IN, NOT IN, INCLUDES or EXCLUDES
+	Operator string
+	Right    Value
+}
+
+// Pos implements Node
+func (c *Comparison) Pos() Position { return c.Left.Pos() }
+
+// LogicalCondition joins conditions with AND or OR. SOQL does not allow AND and OR
+// at the same level without parentheses, so each level has one operator.
+type LogicalCondition struct {
+	Operator   string
+	Conditions []Condition
+}
+
+// Pos implements Node
+func (l *LogicalCondition) Pos() Position { return l.Conditions[0].Pos() }
+
+// NotCondition negates a condition
+type NotCondition struct {
+	Condition Condition
+	StartPos  Position
+}
+
+// Pos implements Node
+func (n *NotCondition) Pos() Position { return n.StartPos }
+
+func (*Comparison) condition()       {}
+func (*LogicalCondition) condition() {}
+func (*NotCondition) condition()     {}
+
+// Value is the right-hand side of a comparison, or a LIMIT or OFFSET value
+type Value interface {
+	Node
+	value()
+}
+
+// StringLiteral is a quoted string. Raw holds the escaped text between the quotes.
+type StringLiteral struct {
+	Raw      string
+	StartPos Position
+}
+
+// NumberLiteral is an integer or decimal number
+type NumberLiteral struct {
+	Raw      string
+	StartPos Position
+}
+
+// BooleanLiteral is TRUE or FALSE
+type BooleanLiteral struct {
+	Value    bool
+	StartPos Position
+}
+
+// NullLiteral is NULL
+type NullLiteral struct {
+	StartPos Position
+}
+
+// DateTimeLiteral is a date such as 2024-01-31 or a datetime such as 2024-01-31T00:00:00Z
+type DateTimeLiteral struct {
+	Raw      string
+	StartPos Position
+}
+
+// DateLiteral is a relative date such as TODAY, LAST_MONTH or LAST_N_DAYS:30. N is set
+// for the literals that take a number.
+type DateLiteral struct {
+	Name     string
+	N        *int
+	StartPos Position
+}
+
+// CurrencyLiteral is an amount with an ISO currency code, such as USD5000
+type CurrencyLiteral struct {
+	Raw      string
+	StartPos Position
+}
+
+// BindVariable is an Apex bind variable such as :accountIds
+type BindVariable struct {
+	Name     string
+	StartPos Position
+}
+
+// ListValue is a parenthesized list of values for IN, NOT IN, INCLUDES and EXCLUDES
+type ListValue struct {
+	Values   []Value
+	StartPos Position
+}
+
+// Pos implements Node
+func (v *StringLiteral) Pos() Position { return v.StartPos }
+
+// Pos implements Node
+func (v *NumberLiteral) Pos() Position { return v.StartPos }
+
+// Pos implements Node
+func (v *BooleanLiteral) Pos() Position { return v.StartPos }
+
+// Pos implements Node
+func (v *NullLiteral) Pos() Position { return v.StartPos }
+
+// Pos implements Node
+func (v *DateTimeLiteral) Pos() Position { return v.StartPos }
+
+// Pos implements Node
+func (v *DateLiteral) Pos() Position { return v.StartPos }
+
+// Pos implements Node
+func (v *CurrencyLiteral) Pos() Position { return v.StartPos }
+
+// Pos implements Node
+func (v *BindVariable) Pos() Position { return v.StartPos }
+
+// Pos implements Node
+func (v *ListValue) Pos() Position { return v.StartPos }
+
+func (*StringLiteral) value()   {}
+func (*NumberLiteral) value()   {}
+func (*BooleanLiteral) value()  {}
+func (*NullLiteral) value()     {}
+func (*DateTimeLiteral) value() {}
+func (*DateLiteral) value()     {}
+func (*CurrencyLiteral) value() {}
+func (*BindVariable) value()    {}
+func (*ListValue) value()       {}
+func (*Query) value()           {}
+
+// Value returns the unescaped string
+func (v *StringLiteral) Value() string {
+	var value strings.Builder
+	for i := 0; i < len(v.Raw); i++ {
+		if v.Raw[i] != '\\' || i+1 == len(v.Raw) {
+			value.WriteByte(v.Raw[i])
+			continue
+		}
+		i++
+		switch v.Raw[i] {
+		case 'n':
+			value.WriteByte('\n')
+		case 'r':
+			value.WriteByte('\r')
+		case 't':
+			value.WriteByte('\t')
+		case 'b':
+			value.WriteByte('\b')
+		case 'f':
+			value.WriteByte('\f')
+		case 'u':
+			if i+4 < len(v.Raw) {
+				if code, err := strconv.ParseUint(v.Raw[i+1:i+5], 16, 32); err == nil {
+					value.WriteRune(rune(code))
+					i += 4
+					continue
+				}
+			}
+			value.WriteByte('u')
+		case '_', '%':
+			// LIKE wildcard escapes keep their backslash
+			value.WriteByte('\\')
+			value.WriteByte(v.Raw[i])
+		default:
+			value.WriteByte(v.Raw[i])
+		}
+	}
+	return value.String()
+}
+
+// NewStringLiteral returns a string literal for an unescaped value
+func NewStringLiteral(value string) *StringLiteral {
+	replacer := strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\n", `\n`, "\r", `\r`, "\t", `\t`, "\b", `\b`, "\f", `\f`)
+	return &StringLiteral{Raw: replacer.Replace(value)}
+}
+
+// Inspect traverses the AST in depth-first order, calling fn for each node. When fn
+// returns false the node's children are skipped.
+func Inspect(node Node, fn func(Node) bool) {
+	if node == nil || !fn(node) {
+		return
+	}
+
+	switch n := node.(type) {
+	case *Query:
+		for _, item := range n.Select {
+			Inspect(item, fn)
+		}
+		if n.From != nil {
+			Inspect(n.From, fn)
+		}
+		if n.Where != nil {
+			Inspect(n.Where, fn)
+		}
+		if n.With != nil {
+			Inspect(n.With, fn)
+		}
+		if n.GroupBy != nil {
+			Inspect(n.GroupBy, fn)
+		}
+		if n.Having != nil {
+			Inspect(n.Having, fn)
+		}
+		for _, item := range n.OrderBy {
+			Inspect(item, fn)
+		}
+		if n.Limit != nil {
+			Inspect(n.Limit, fn)
+		}
+		if n.Offset != nil {
+			Inspect(n.Offset, fn)
+		}
+	case *Search:
+		for _, object := range n.Returning {
+			Inspect(object, fn)
+		}
+	case *ReturningObject:
+		Inspect(n.Object, fn)
+		for _, field := range n.Fields {
+			Inspect(field, fn)
+		}
+		if n.Where != nil {
+			Inspect(n.Where, fn)
+		}
+		for _, item := range n.OrderBy {
+			Inspect(item, fn)
+		}
+		if n.Limit != nil {
+			Inspect(n.Limit, fn)
+		}
+		if n.Offset != nil {
+			Inspect(n.Offset, fn)
+		}
+	case *SelectExpr:
+		Inspect(n.Expr, fn)
+	case *TypeOf:
+		for _, when := range n.Whens {
+			Inspect(when, fn)
+		}
+		for _, field := range n.Else {
+			Inspect(field, fn)
+		}
+	case *TypeOfWhen:
+		for _, field := range n.Fields {
+			Inspect(field, fn)
+		}
+	case *FunctionCall:
+		for _, arg := range n.Args {
+			Inspect(arg, fn)
+		}
+	case *WithClause:
+		for _, filter := range n.DataCategories {
+			Inspect(filter, fn)
+		}
+	case *GroupBy:
+		for _, expr := range n.Exprs {
+			Inspect(expr, fn)
+		}
+	case *OrderItem:
+		Inspect(n.Expr, fn)
+	case *Comparison:
+		Inspect(n.Left, fn)
+		Inspect(n.Right, fn)
+	case *LogicalCondition:
+		for _, condition := range n.Conditions {
+			Inspect(condition, fn)
+		}
+	case *NotCondition:
+		Inspect(n.Condition, fn)
+	case *ListValue:
+		for _, value := range n.Values {
+			Inspect(value, fn)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package soql
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStringLiteralValue(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{raw: `plain`, want: "plain"},
+		{raw: `it\'s`, want: "it's"},
+		{raw: `a\nb\tc`, want: "a\nb\tc"},
+		{raw: `back\\slash`, want: `back\slash`},
+		{raw: `caf\u00e9`, want: "café"},
+		{raw: `\u00C9t\u00e9`, want: "Été"},
+		{raw: `50\% off\_`, want: `50\% off\_`},
+		{raw: `\u`, want: "u"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.raw, func(t *testing.T) {
+			literal := &StringLiteral{Raw: test.raw}
+			if got := literal.Value(); got != test.want {
+				t.Errorf("Value() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestNewStringLiteral(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "plain", want: "'plain'"},
+		{value: "it's", want: `'it\'s'`},
+		{value: "a\nb", want: `'a\nb'`},
+		{value: `back\slash`, want: `'back\\slash'`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			literal := NewStringLiteral(test.value)
+			if got := literal.String(); got != test.want {
+				t.Errorf("String() = %q, want %q", got, test.want)
+			}
+			if got := literal.Value(); got != test.value {
+				t.Errorf("Value() = %q, want %q", got, test.value)
+			}
+		})
+	}
+}
+
+func TestInspect(t *testing.T) {
+	query, err := Parse("SELECT Id, Account.Name, (SELECT Email FROM Contacts) FROM Opportunity WHERE Amount > 10 ORDER BY CloseDate")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		// skipSubqueries stops the walk at nested queries
+		skipSubqueries bool
+		want           []string
+	}{
+		{name: "all fields", want: []string{"Id", "Account.Name", "Email", "Amount", "CloseDate"}},
+		{name: "top level fields", skipSubqueries: true, want: []string{"Id", "Account.Name", "Amount", "CloseDate"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var fields []string
+			Inspect(query, func(node Node) bool {
+				switch n := node.(type) {
+				case *Query:
+					return n == query || !test.skipSubqueries
+				case *FieldRef:
+					fields = append(fields, n.Name())
+				}
+				return true
+			})
+			if !reflect.DeepEqual(fields, test.want) {
+				t.Errorf("fields = %q, want %q", fields, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,337 @@
+package soql
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// TokenKind identifies the lexical class of a token
+type TokenKind int
+
+// Token kinds
+const (
+	TokenEOF TokenKind = iota
+	TokenIdent
+	TokenString
+	TokenNumber
+	TokenDate
+	TokenOperator
+	TokenComma
+	TokenDot
+	TokenColon
+	TokenLParen
+	TokenRParen
+	TokenBraces
+)
+
+func (k TokenKind) String() string {
+	switch k {
+	case TokenEOF:
+		return "end of query"
+	case TokenIdent:
+		return "identifier"
+	case TokenString:
+		return "string"
+	case TokenNumber:
+		return "number"
+	case TokenDate:
+		return "date"
+	case TokenOperator:
+		return "operator"
+	case TokenComma:
+		return "','"
+	case TokenDot:
+		return "'.'"
+	case TokenColon:
+		return "':'"
+	case TokenLParen:
+		return "'('"
+	case TokenRParen:
+		return "')'"
+	case TokenBraces:
+		return "search term"
+	}
+	return "token"
+}
+
+// Position is a location in the query text. Line and Column start at 1.
+type Position struct {
+	Offset int
+	Line   int
+	Column int
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("line %d:%d", p.Line, p.Column)
+}
+
+// Token is a lexical token. Text holds the source text; for strings it is the escaped
+// text between the quotes.
+type Token struct {
+	Kind TokenKind
+	Text string
+	Pos  Position
+}
+
+// is reports whether the token is the given keyword, ignoring case
+func (t Token) is(keyword string) bool {
+	return t.Kind == TokenIdent && strings.EqualFold(t.Text, keyword)
+}
+
+func (t Token) String() string {
+	switch t.Kind {
+	case TokenEOF:
+		return t.Kind.String()
+	case TokenString:
+		return "'" + t.Text + "'"
+	case TokenBraces:
+		return "{" + t.Text + "}"
+	}
+	return fmt.Sprintf("%q", t.Text)
+}
+
+// lexer splits a query into tokens
+type lexer struct {
+	input  []rune
+	offset int
+	line   int
+	column int
+}
+
+// tokenize returns the tokens of a query, ending with a TokenEOF token
+func tokenize(input string) ([]Token, error) {
+	l := &lexer{input: []rune(input), line: 1, column: 1}
+	var tokens []Token
+	for {
+		token, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+		if token.Kind == TokenEOF {
+			return tokens, nil
+		}
+	}
+}
+
+func (l *lexer) pos() Position {
+	return Position{Offset: l.offset, Line: l.line, Column: l.column}
+}
+
+func (l *lexer) peek(ahead int) rune {
+	if l.offset+ahead >= len(l.input) {
+		return 0
+	}
+	return l.input[l.offset+ahead]
+}
+
+func (l *lexer) advance() rune {
+	r := l.input[l.offset]
+	l.offset++
+	if r == '\n' {
+		l.line++
+		l.column = 1
+	} else {
+		l.column++
+	}
+	return r
+}
+
+func (l *lexer) next() (Token, error) {
+	for l.offset < len(l.input) && unicode.IsSpace(l.peek(0)) {
+		l.advance()
+	}
+
+	start := l.pos()
+	if l.offset >= len(l.input) {
+		return Token{Kind: TokenEOF, Pos: start}, nil
+	}
+
+	r := l.peek(0)
+	switch {
+	case r == '\'':
+		return l.string(start)
+	case r == '{':
+		return l.braces(start)
+	case isDigit(r) || (r == '.' && isDigit(l.peek(1))):
+		return l.number(start), nil
+	case (r == '-' || r == '+') && (isDigit(l.peek(1)) || (l.peek(1) == '.' && isDigit(l.peek(2)))):
+		l.advance()
+		token := l.number(start)
+		token.Text = string(r) + token.Text
+		return token, nil
+	case isIdentStart(r):
+		return l.ident(start), nil
+	}
+
+	l.advance()
+	switch r {
+	case ',':
+		return Token{Kind: TokenComma, Text: ",", Pos: start}, nil
+	case '.':
+		return Token{Kind: TokenDot, Text: ".", Pos: start}, nil
+	case ':':
+		return Token{Kind: TokenColon, Text: ":", Pos: start}, nil
+	case '(':
+		return Token{Kind: TokenLParen, Text: "(", Pos: start}, nil
+	case ')':
+		return Token{Kind: TokenRParen, Text: ")", Pos: start}, nil
+	case '=':
+		return Token{Kind: TokenOperator, Text: "=", Pos: start}, nil
+	case '!':
+		if l.peek(0) == '=' {
+			l.advance()
+			return Token{Kind: TokenOperator, Text: "!=", Pos: start}, nil
+		}
+	case '<':
+		if l.peek(0) == '=' || l.peek(0) == '>' {
+			return Token{Kind: TokenOperator, Text: "<" + string(l.advance()), Pos: start}, nil
+		}
+		return Token{Kind: TokenOperator, Text: "<", Pos: start}, nil
+	case '>':
+		if l.peek(0) == '=' {
+			l.advance()
+			return Token{Kind: TokenOperator, Text: ">=", Pos: start}, nil
+		}
+		return Token{Kind: TokenOperator, Text: ">", Pos: start}, nil
+	}
+	return Token{}, &ParseError{Pos: start, Message: fmt.Sprintf("unexpected character %q", r)}
+}
+
+// string scans a single quoted string literal. The token text is the escaped source
+// between the quotes.
+func (l *lexer) string(start Position) (Token, error) {
+	l.advance()
+	begin := l.offset
+	for l.offset < len(l.input) {
+		switch l.advance() {
+		case '\'':
+			return Token{Kind: TokenString, Text: string(l.input[begin : l.offset-1]), Pos: start}, nil
+		case '\\':
+			escapePos := l.pos()
+			if l.offset >= len(l.input) {
+				break
+			}
+			escaped := l.advance()
+			if escaped == 'u' {
+				// Unicode escapes take exactly four hex digits, e.g. \u00e9
+				for i := 0; i < 4; i++ {
+					if !isHexDigit(l.peek(0)) {
+						escapePos.Column--
+						escapePos.Offset--
+						return Token{}, &ParseError{Pos: escapePos, Message: "invalid escape sequence \\u: expected four hex digits"}
+					}
+					l.advance()
+				}
+			} else if !strings.ContainsRune(`nrtbf"'\\_%`, escaped) {
+				escapePos.Column--
+				escapePos.Offset--
+				return Token{}, &ParseError{Pos: escapePos, Message: fmt.Sprintf("invalid escape sequence \\%c", escaped)}
+			}
+		}
+	}
+	return Token{}, &ParseError{Pos: start, Message: "unterminated string literal"}
+}
+
+// braces scans a SOSL search term such as {Acme*}. The token text is the source between
+// the braces; a backslash escapes the next character.
+func (l *lexer) braces(start Position) (Token, error) {
+	l.advance()
+	begin := l.offset
+	for l.offset < len(l.input) {
+		switch l.advance() {
+		case '}':
+			return Token{Kind: TokenBraces, Text: string(l.input[begin : l.offset-1]), Pos: start}, nil
+		case '\\':
+			if l.offset < len(l.input) {
+				l.advance()
+			}
+		}
+	}
+	return Token{}, &ParseError{Pos: start, Message: "unterminated search term"}
+}
+
+// number scans a number, or a date or datetime literal such as 2024-01-31 or
+// 2024-01-31T08:00:00.000+0100
+func (l *lexer) number(start Position) Token {
+	begin := l.offset
+	for isDigit(l.peek(0)) {
+		l.advance()
+	}
+
+	// Dates start with a four digit year followed by a dash
+	if l.offset-begin == 4 && l.peek(0) == '-' && isDigit(l.peek(1)) {
+		for isDigit(l.peek(0)) || strings.ContainsRune("-:.TZ+", l.peek(0)) {
+			l.advance()
+		}
+		return Token{Kind: TokenDate, Text: string(l.input[begin:l.offset]), Pos: start}
+	}
+
+	if l.peek(0) == '.' && isDigit(l.peek(1)) {
+		l.advance()
+		for isDigit(l.peek(0)) {
+			l.advance()
+		}
+	}
+	if (l.peek(0) == 'e' || l.peek(0) == 'E') && (isDigit(l.peek(1)) ||
+		((l.peek(1) == '-' || l.peek(1) == '+') && isDigit(l.peek(2)))) {
+		l.advance()
+		l.advance()
+		for isDigit(l.peek(0)) {
+			l.advance()
+		}
+	}
+	return Token{Kind: TokenNumber, Text: string(l.input[begin:l.offset]), Pos: start}
+}
+
+// ident scans an identifier or keyword. Currency literals such as USD5000.50 are scanned
+// as one identifier.
+func (l *lexer) ident(start Position) Token {
+	begin := l.offset
+	for isIdentPart(l.peek(0)) {
+		l.advance()
+	}
+	text := string(l.input[begin:l.offset])
+	if isCurrencyLiteral(text) && l.peek(0) == '.' && isDigit(l.peek(1)) {
+		l.advance()
+		for isDigit(l.peek(0)) {
+			l.advance()
+		}
+		text = string(l.input[begin:l.offset])
+	}
+	return Token{Kind: TokenIdent, Text: text, Pos: start}
+}
+
+// isCurrencyLiteral reports whether text is an ISO currency code followed by an amount,
+// e.g. USD5000 or EUR12.50
+func isCurrencyLiteral(text string) bool {
+	if len(text) < 4 {
+		return false
+	}
+	for i, r := range text {
+		switch {
+		case i < 3 && !(r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z'):
+			return false
+		case i >= 3 && !isDigit(r) && r != '.':
+			return false
+		}
+	}
+	return isDigit(rune(text[3]))
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func isHexDigit(r rune) bool {
+	return isDigit(r) || r >= 'a' && r <= 'f' || r >= 'A' && r <= 'F'
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isIdentPart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
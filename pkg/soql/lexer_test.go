@@ -0,0 +1,96 @@
+package soql
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		kinds []TokenKind
+		texts []string
+	}{
+		{
+			name:  "operators and punctuation",
+			input: "a.b, (c) <> :d",
+			kinds: []TokenKind{TokenIdent, TokenDot, TokenIdent, TokenComma, TokenLParen, TokenIdent, TokenRParen, TokenOperator, TokenColon, TokenIdent, TokenEOF},
+			texts: []string{"a", ".", "b", ",", "(", "c", ")", "<>", ":", "d", ""},
+		},
+		{
+			name:  "numbers and dates",
+			input: "-1.5 2024-01-31 2024-01-31T08:00:00.000+0100 1e3",
+			kinds: []TokenKind{TokenNumber, TokenDate, TokenDate, TokenNumber, TokenEOF},
+			texts: []string{"-1.5", "2024-01-31", "2024-01-31T08:00:00.000+0100", "1e3", ""},
+		},
+		{
+			name:  "currency literal",
+			input: "EUR12.50",
+			kinds: []TokenKind{TokenIdent, TokenEOF},
+			texts: []string{"EUR12.50", ""},
+		},
+		{
+			name:  "search term",
+			input: `FIND {Acme \} 'Corp'} IN`,
+			kinds: []TokenKind{TokenIdent, TokenBraces, TokenIdent, TokenEOF},
+			texts: []string{"FIND", `Acme \} 'Corp'`, "IN", ""},
+		},
+		{
+			name:  "string escapes",
+			input: `'it\'s \n \\ \% \_ \u00e9 \u00C9'`,
+			kinds: []TokenKind{TokenString, TokenEOF},
+			texts: []string{`it\'s \n \\ \% \_ \u00e9 \u00C9`, ""},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tokens, err := tokenize(test.input)
+			if err != nil {
+				t.Fatalf("tokenize(%q) failed: %v", test.input, err)
+			}
+			var kinds []TokenKind
+			var texts []string
+			for _, token := range tokens {
+				kinds = append(kinds, token.Kind)
+				texts = append(texts, token.Text)
+			}
+			if !reflect.DeepEqual(kinds, test.kinds) {
+				t.Errorf("kinds = %v, want %v", kinds, test.kinds)
+			}
+			if !reflect.DeepEqual(texts, test.texts) {
+				t.Errorf("texts = %q, want %q", texts, test.texts)
+			}
+		})
+	}
+}
+
+func TestTokenizeErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "unterminated string", input: "Name = 'abc", want: "line 1:8: unterminated string literal"},
+		{name: "unterminated search term", input: `FIND {abc\}`, want: "line 1:6: unterminated search term"},
+		{name: "invalid escape", input: `'a\qb'`, want: `line 1:3: invalid escape sequence \q`},
+		{name: "short unicode escape", input: `'a\u00zz'`, want: `line 1:3: invalid escape sequence \u: expected four hex digits`},
+		{name: "unicode escape at end", input: `'a\u12'`, want: `line 1:3: invalid escape sequence \u: expected four hex digits`},
+		{name: "unexpected character", input: "a # b", want: "line 1:3: unexpected character '#'"},
+		{name: "lone bang", input: "a ! b", want: "line 1:3: unexpected character '!'"},
+		{name: "error on second line", input: "a\n  'b", want: "line 2:3: unterminated string literal"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := tokenize(test.input)
+			if err == nil {
+				t.Fatalf("tokenize(%q) succeeded, want error %q", test.input, test.want)
+			}
+			if err.Error() != test.want {
+				t.Errorf("tokenize(%q) error = %q, want %q", test.input, err.Error(), test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,864 @@
+package soql
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ParseError is returned for a query that cannot be parsed. Pos points at the offending token.
+type ParseError struct {
+	Pos     Position
+	Message string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Pos, e.Message)
+}
+
+// clauseKeywords can start a clause or end a select list item, so they are never read
+// as an alias. Where a name is expected they are ordinary names, as in FROM Order.
+var clauseKeywords = map[string]bool{
+	"FROM": true, "WHERE": true, "WITH": true, "GROUP": true, "HAVING": true, "ORDER": true,
+	"LIMIT": true, "OFFSET": true, "FOR": true, "UPDATE": true, "USING": true, "AND": true,
+	"OR": true, "NOT": true, "ASC": true, "DESC": true, "NULLS": true, "WHEN": true,
+	"THEN": true, "ELSE": true, "END": true,
+}
+
+// dateLiterals are the relative date literals that take no number
+var dateLiterals = map[string]bool{
+	"YESTERDAY": true, "TODAY": true, "TOMORROW": true,
+	"LAST_WEEK": true, "THIS_WEEK": true, "NEXT_WEEK": true,
+	"LAST_MONTH": true, "THIS_MONTH": true, "NEXT_MONTH": true,
+	"LAST_90_DAYS": true, "NEXT_90_DAYS": true,
+	"LAST_QUARTER": true, "THIS_QUARTER": true, "NEXT_QUARTER": true,
+	"LAST_YEAR": true, "THIS_YEAR": true, "NEXT_YEAR": true,
+	"LAST_FISCAL_QUARTER": true, "THIS_FISCAL_QUARTER": true, "NEXT_FISCAL_QUARTER": true,
+	"LAST_FISCAL_YEAR": true, "THIS_FISCAL_YEAR": true, "NEXT_FISCAL_YEAR": true,
+}
+
+// dateLiteralN matches the relative date literals that take a number, e.g. LAST_N_DAYS:30
+var dateLiteralN = regexp.MustCompile(`^(?:(?:LAST|NEXT)_N_(?:DAYS|WEEKS|MONTHS|QUARTERS|YEARS|FISCAL_QUARTERS|FISCAL_YEARS)|N_(?:DAYS|WEEKS|MONTHS|QUARTERS|YEARS|FISCAL_QUARTERS|FISCAL_YEARS)_AGO)$`)
+
+// Parse parses a SOQL SELECT statement
+func Parse(query string) (*Query, error) {
+	tokens, err := tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	q, err := p.query()
+	if err != nil {
+		return nil, err
+	}
+	if token := p.peek(); token.Kind != TokenEOF {
+		return nil, p.errorf(token, "unexpected %s after end of query", token)
+	}
+	return q, nil
+}
+
+// ParseSearch parses a SOSL search such as
+// FIND {Acme} IN NAME FIELDS RETURNING Account(Name WHERE Industry = 'Tech'), Contact.
+// The RETURNING clause is parsed into nodes; the clauses after it are only checked to
+// not hold a second RETURNING clause.
+func ParseSearch(search string) (*Search, error) {
+	tokens, err := tokenize(search)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	start, err := p.expect("FIND")
+	if err != nil {
+		return nil, err
+	}
+	if term := p.next(); term.Kind != TokenBraces && term.Kind != TokenString {
+		return nil, p.errorf(term, "expected search term, found %s", term)
+	}
+	result := &Search{StartPos: start.Pos}
+	if p.accept("IN") {
+		group, err := p.identifier("search group")
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect("FIELDS"); err != nil {
+			return nil, err
+		}
+		result.In = strings.ToUpper(group.Text)
+	}
+
+	runes := []rune(search)
+	returning := p.peek()
+	result.Prefix = strings.TrimSpace(string(runes[:returning.Pos.Offset]))
+	if p.accept("RETURNING") {
+		for {
+			object, err := p.returningObject()
+			if err != nil {
+				return nil, err
+			}
+			result.Returning = append(result.Returning, object)
+			if !p.acceptKind(TokenComma) {
+				break
+			}
+		}
+	}
+	result.Suffix = strings.TrimSpace(string(runes[p.peek().Pos.Offset:]))
+
+	for ; p.peek().Kind != TokenEOF; p.next() {
+		if token := p.peek(); token.is("RETURNING") {
+			return nil, p.errorf(token, "RETURNING must directly follow the search term and IN clause")
+		}
+	}
+	return result, nil
+}
+
+// parser is a recursive descent parser over the tokens of a query
+type parser struct {
+	tokens []Token
+	pos    int
+}
+
+func (p *parser) peek() Token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) peekAt(ahead int) Token {
+	if p.pos+ahead >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.pos+ahead]
+}
+
+func (p *parser) next() Token {
+	token := p.tokens[p.pos]
+	if token.Kind != TokenEOF {
+		p.pos++
+	}
+	return token
+}
+
+// accept consumes the next token if it is the given keyword
+func (p *parser) accept(keyword string) bool {
+	if p.peek().is(keyword) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// acceptKind consumes the next token if it has the given kind
+func (p *parser) acceptKind(kind TokenKind) bool {
+	if p.peek().Kind == kind {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(keyword string) (Token, error) {
+	token := p.next()
+	if !token.is(keyword) {
+		return token, p.errorf(token, "expected %s, found %s", keyword, token)
+	}
+	return token, nil
+}
+
+func (p *parser) expectKind(kind TokenKind) (Token, error) {
+	token := p.next()
+	if token.Kind != kind {
+		return token, p.errorf(token, "expected %s, found %s", kind, token)
+	}
+	return token, nil
+}
+
+// expectOneOf consumes one of the given keywords and returns it in upper case
+func (p *parser) expectOneOf(keywords ...string) (string, error) {
+	token := p.next()
+	for _, keyword := range keywords {
+		if token.is(keyword) {
+			return keyword, nil
+		}
+	}
+	return "", p.errorf(token, "expected %s, found %s", strings.Join(keywords, " or "), token)
+}
+
+func (p *parser) errorf(token Token, format string, args ...any) error {
+	return &ParseError{Pos: token.Pos, Message: fmt.Sprintf(format, args...)}
+}
+
+// identifier consumes a name. Keywords are accepted because objects and fields such as
+// Order, Group or Case share their names.
+func (p *parser) identifier(what string) (Token, error) {
+	token := p.next()
+	if token.Kind != TokenIdent {
+		return token, p.errorf(token, "expected %s, found %s", what, token)
+	}
+	return token, nil
+}
+
+// isAlias reports whether the next token is an alias for the preceding item
+func (p *parser) isAlias() bool {
+	token := p.peek()
+	return token.Kind == TokenIdent && !clauseKeywords[strings.ToUpper(token.Text)]
+}
+
+func (p *parser) query() (*Query, error) {
+	start, err := p.expect("SELECT")
+	if err != nil {
+		return nil, err
+	}
+	q := &Query{StartPos: start.Pos}
+
+	for {
+		item, err := p.selectItem()
+		if err != nil {
+			return nil, err
+		}
+		q.Select = append(q.Select, item)
+		if !p.acceptKind(TokenComma) {
+			break
+		}
+	}
+
+	if _, err := p.expect("FROM"); err != nil {
+		return nil, err
+	}
+	object, err := p.identifier("object name")
+	if err != nil {
+		return nil, err
+	}
+	q.From = &ObjectRef{Name: object.Text, StartPos: object.Pos}
+	if p.isAlias() {
+		q.From.Alias = p.next().Text
+	}
+
+	if p.accept("USING") {
+		if _, err := p.expect("SCOPE"); err != nil {
+			return nil, err
+		}
+		scope, err := p.identifier("scope")
+		if err != nil {
+			return nil, err
+		}
+		q.Scope = scope.Text
+	}
+
+	if p.accept("WHERE") {
+		if q.Where, err = p.condition(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.peek().is("WITH") {
+		if q.With, err = p.withClause(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.peek().is("GROUP") {
+		if q.GroupBy, err = p.groupBy(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.accept("HAVING") {
+		if q.Having, err = p.condition(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.accept("ORDER") {
+		if _, err := p.expect("BY"); err != nil {
+			return nil, err
+		}
+		for {
+			item, err := p.orderItem()
+			if err != nil {
+				return nil, err
+			}
+			q.OrderBy = append(q.OrderBy, item)
+			if !p.acceptKind(TokenComma) {
+				break
+			}
+		}
+	}
+
+	if p.accept("LIMIT") {
+		if q.Limit, err = p.integerOrBind("LIMIT"); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.accept("OFFSET") {
+		if q.Offset, err = p.integerOrBind("OFFSET"); err != nil {
+			return nil, err
+		}
+	}
+
+	for {
+		if p.accept("FOR") {
+			option, err := p.expectOneOf("VIEW", "REFERENCE", "UPDATE")
+			if err != nil {
+				return nil, err
+			}
+			q.For = append(q.For, option)
+		} else if p.accept("UPDATE") {
+			option, err := p.expectOneOf("TRACKING", "VIEWSTAT")
+			if err != nil {
+				return nil, err
+			}
+			q.Update = append(q.Update, option)
+		} else {
+			break
+		}
+	}
+
+	return q, nil
+}
+
+// returningObject parses one object of a SOSL RETURNING clause with its optional field
+// list, USING ListView, WHERE, ORDER BY, LIMIT and OFFSET
+func (p *parser) returningObject() (*ReturningObject, error) {
+	name, err := p.identifier("object name")
+	if err != nil {
+		return nil, err
+	}
+	object := &ReturningObject{Object: &ObjectRef{Name: name.Text, StartPos: name.Pos}}
+	if !p.acceptKind(TokenLParen) {
+		return object, nil
+	}
+
+	for {
+		expr, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		field := &SelectExpr{Expr: expr}
+		if _, ok := expr.(*FunctionCall); ok && p.isAlias() {
+			field.Alias = p.next().Text
+		}
+		object.Fields = append(object.Fields, field)
+		if !p.acceptKind(TokenComma) {
+			break
+		}
+	}
+
+	if p.accept("USING") {
+		if _, err := p.expect("ListView"); err != nil {
+			return nil, err
+		}
+		if token := p.next(); token.Kind != TokenOperator || token.Text != "=" {
+			return nil, p.errorf(token, "expected '=', found %s", token)
+		}
+		listView, err := p.identifier("list view")
+		if err != nil {
+			return nil, err
+		}
+		object.ListView = listView.Text
+	}
+	if p.accept("WHERE") {
+		if object.Where, err = p.condition(); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept("ORDER") {
+		if _, err := p.expect("BY"); err != nil {
+			return nil, err
+		}
+		for {
+			item, err := p.orderItem()
+			if err != nil {
+				return nil, err
+			}
+			object.OrderBy = append(object.OrderBy, item)
+			if !p.acceptKind(TokenComma) {
+				break
+			}
+		}
+	}
+	if p.accept("LIMIT") {
+		if object.Limit, err = p.integerOrBind("LIMIT"); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept("OFFSET") {
+		if object.Offset, err = p.integerOrBind("OFFSET"); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := p.expectKind(TokenRParen); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+func (p *parser) selectItem() (SelectItem, error) {
+	token := p.peek()
+	switch {
+	case token.Kind == TokenLParen:
+		return p.subquery()
+	case token.is("TYPEOF") && p.peekAt(1).Kind == TokenIdent && !p.peekAt(1).is("FROM"):
+		return p.typeOf()
+	case token.is("FROM") && p.peekAt(1).Kind == TokenIdent && !p.peekAt(1).is("FROM"):
+		// FROM followed by a name ends an empty or unfinished select list
+		return nil, p.errorf(token, "expected field name, found %s", token)
+	}
+
+	expr, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	item := &SelectExpr{Expr: expr}
+	if _, ok := expr.(*FunctionCall); ok && p.isAlias() {
+		item.Alias = p.next().Text
+	}
+	return item, nil
+}
+
+// subquery parses a parenthesized SELECT statement
+func (p *parser) subquery() (*Query, error) {
+	if _, err := p.expectKind(TokenLParen); err != nil {
+		return nil, err
+	}
+	q, err := p.query()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.expectKind(TokenRParen); err != nil {
+		return nil, err
+	}
+	q.Subquery = true
+	return q, nil
+}
+
+// typeOf parses TYPEOF <relationship> WHEN <type> THEN <fields> ... [ELSE <fields>] END
+func (p *parser) typeOf() (*TypeOf, error) {
+	start := p.next()
+	relationship, err := p.identifier("relationship name")
+	if err != nil {
+		return nil, err
+	}
+	typeOf := &TypeOf{Relationship: relationship.Text, StartPos: start.Pos, RelationshipPos: relationship.Pos}
+
+	for p.peek().is("WHEN") {
+		when := p.next()
+		objectType, err := p.identifier("object type")
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect("THEN"); err != nil {
+			return nil, err
+		}
+		fields, err := p.fieldList()
+		if err != nil {
+			return nil, err
+		}
+		typeOf.Whens = append(typeOf.Whens, &TypeOfWhen{
+			Type:     objectType.Text,
+			Fields:   fields,
+			StartPos: when.Pos,
+			TypePos:  objectType.Pos,
+		})
+	}
+	if len(typeOf.Whens) == 0 {
+		return nil, p.errorf(p.peek(), "expected WHEN, found %s", p.peek())
+	}
+
+	if p.accept("ELSE") {
+		if typeOf.Else, err = p.fieldList(); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := p.expect("END"); err != nil {
+		return nil, err
+	}
+	return typeOf, nil
+}
+
+func (p *parser) fieldList() ([]*FieldRef, error) {
+	var fields []*FieldRef
+	for {
+		field, err := p.fieldRef()
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+		if !p.acceptKind(TokenComma) {
+			return fields, nil
+		}
+	}
+}
+
+// expr parses a field reference or a function call
+func (p *parser) expr() (Expr, error) {
+	if p.peek().Kind == TokenIdent && p.peekAt(1).Kind == TokenLParen {
+		return p.functionCall()
+	}
+	return p.fieldRef()
+}
+
+func (p *parser) fieldRef() (*FieldRef, error) {
+	name, err := p.identifier("field name")
+	if err != nil {
+		return nil, err
+	}
+	field := &FieldRef{Path: []string{name.Text}, PathPos: []Position{name.Pos}, StartPos: name.Pos}
+	for p.acceptKind(TokenDot) {
+		part, err := p.expectKind(TokenIdent)
+		if err != nil {
+			return nil, err
+		}
+		field.Path = append(field.Path, part.Text)
+		field.PathPos = append(field.PathPos, part.Pos)
+	}
+	return field, nil
+}
+
+func (p *parser) functionCall() (*FunctionCall, error) {
+	name := p.next()
+	p.next()
+	call := &FunctionCall{Name: name.Text, StartPos: name.Pos}
+	if p.acceptKind(TokenRParen) {
+		return call, nil
+	}
+
+	for {
+		var arg Node
+		switch token := p.peek(); token.Kind {
+		case TokenString:
+			p.next()
+			arg = &StringLiteral{Raw: token.Text, StartPos: token.Pos}
+		case TokenNumber:
+			p.next()
+			arg = &NumberLiteral{Raw: token.Text, StartPos: token.Pos}
+		default:
+			expr, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			arg = expr
+		}
+		call.Args = append(call.Args, arg)
+		if !p.acceptKind(TokenComma) {
+			break
+		}
+	}
+
+	if _, err := p.expectKind(TokenRParen); err != nil {
+		return nil, err
+	}
+	return call, nil
+}
+
+// withClause parses WITH SECURITY_ENFORCED, USER_MODE, SYSTEM_MODE or DATA CATEGORY filters
+func (p *parser) withClause() (*WithClause, error) {
+	start := p.next()
+	with := &WithClause{StartPos: start.Pos}
+	if !p.accept("DATA") {
+		kind, err := p.expectOneOf("SECURITY_ENFORCED", "USER_MODE", "SYSTEM_MODE", "DATA CATEGORY")
+		if err != nil {
+			return nil, err
+		}
+		with.Kind = kind
+		return with, nil
+	}
+
+	if _, err := p.expect("CATEGORY"); err != nil {
+		return nil, err
+	}
+	with.Kind = "DATA CATEGORY"
+	for {
+		group, err := p.identifier("data category group")
+		if err != nil {
+			return nil, err
+		}
+		selector, err := p.expectOneOf("AT", "ABOVE", "BELOW", "ABOVE_OR_BELOW")
+		if err != nil {
+			return nil, err
+		}
+		filter := &DataCategoryFilter{Group: group.Text, Selector: selector, StartPos: group.Pos}
+
+		parenthesized := p.acceptKind(TokenLParen)
+		for {
+			category, err := p.identifier("data category")
+			if err != nil {
+				return nil, err
+			}
+			filter.Categories = append(filter.Categories, category.Text)
+			if !parenthesized || !p.acceptKind(TokenComma) {
+				break
+			}
+		}
+		if parenthesized {
+			if _, err := p.expectKind(TokenRParen); err != nil {
+				return nil, err
+			}
+		}
+
+		with.DataCategories = append(with.DataCategories, filter)
+		if !p.accept("AND") {
+			return with, nil
+		}
+	}
+}
+
+// groupBy parses GROUP BY <exprs>, GROUP BY ROLLUP(<exprs>) or GROUP BY CUBE(<exprs>)
+func (p *parser) groupBy() (*GroupBy, error) {
+	start := p.next()
+	if _, err := p.expect("BY"); err != nil {
+		return nil, err
+	}
+	groupBy := &GroupBy{StartPos: start.Pos}
+
+	wrapped := false
+	if (p.peek().is("ROLLUP") || p.peek().is("CUBE")) && p.peekAt(1).Kind == TokenLParen {
+		groupBy.Kind = strings.ToUpper(p.next().Text)
+		p.next()
+		wrapped = true
+	}
+
+	for {
+		expr, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		groupBy.Exprs = append(groupBy.Exprs, expr)
+		if !p.acceptKind(TokenComma) {
+			break
+		}
+	}
+
+	if wrapped {
+		if _, err := p.expectKind(TokenRParen); err != nil {
+			return nil, err
+		}
+	}
+	return groupBy, nil
+}
+
+func (p *parser) orderItem() (*OrderItem, error) {
+	expr, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	item := &OrderItem{Expr: expr}
+	if p.peek().is("ASC") || p.peek().is("DESC") {
+		item.Direction = strings.ToUpper(p.next().Text)
+	}
+	if p.accept("NULLS") {
+		if item.Nulls, err = p.expectOneOf("FIRST", "LAST"); err != nil {
+			return nil, err
+		}
+	}
+	return item, nil
+}
+
+// integerOrBind parses a LIMIT or OFFSET value
+func (p *parser) integerOrBind(clause string) (Value, error) {
+	token := p.peek()
+	if token.Kind == TokenColon {
+		return p.bindVariable()
+	}
+	p.next()
+	if token.Kind != TokenNumber {
+		return nil, p.errorf(token, "expected a number after %s, found %s", clause, token)
+	}
+	if n, err := strconv.Atoi(token.Text); err != nil || n < 0 {
+		return nil, p.errorf(token, "%s must be a non-negative integer, found %s", clause, token.Text)
+	}
+	return &NumberLiteral{Raw: token.Text, StartPos: token.Pos}, nil
+}
+
+// condition parses conditions joined by AND or OR. Salesforce requires parentheses
+// when AND and OR are mixed, so one level only has one operator.
+func (p *parser) condition() (Condition, error) {
+	first, err := p.unaryCondition()
+	if err != nil {
+		return nil, err
+	}
+
+	operator := ""
+	switch {
+	case p.peek().is("AND"):
+		operator = "AND"
+	case p.peek().is("OR"):
+		operator = "OR"
+	default:
+		return first, nil
+	}
+
+	logical := &LogicalCondition{Operator: operator, Conditions: []Condition{first}}
+	for {
+		token := p.peek()
+		if !token.is("AND") && !token.is("OR") {
+			return logical, nil
+		}
+		if !token.is(operator) {
+			return nil, p.errorf(token, "AND and OR cannot be mixed without parentheses")
+		}
+		p.next()
+		next, err := p.unaryCondition()
+		if err != nil {
+			return nil, err
+		}
+		logical.Conditions = append(logical.Conditions, next)
+	}
+}
+
+func (p *parser) unaryCondition() (Condition, error) {
+	token := p.peek()
+	switch {
+	case token.is("NOT"):
+		p.next()
+		condition, err := p.unaryCondition()
+		if err != nil {
+			return nil, err
+		}
+		return &NotCondition{Condition: condition, StartPos: token.Pos}, nil
+	case token.Kind == TokenLParen:
+		p.next()
+		condition, err := p.condition()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expectKind(TokenRParen); err != nil {
+			return nil, err
+		}
+		return condition, nil
+	}
+	return p.comparison()
+}
+
+func (p *parser) comparison() (*Comparison, error) {
+	left, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+
+	token := p.next()
+	comparison := &Comparison{Left: left}
+	switch {
+	case token.Kind == TokenOperator:
+		comparison.Operator = token.Text
+		if token.Text == "<>" {
+			comparison.Operator = "!="
+		}
+	case token.is("LIKE"), token.is("IN"), token.is("INCLUDES"), token.is("EXCLUDES"):
+		comparison.Operator = strings.ToUpper(token.Text)
+	case token.is("NOT") && p.peek().is("IN"):
+		p.next()
+		comparison.Operator = "NOT IN"
+	default:
+		return nil, p.errorf(token, "expected a comparison operator, found %s", token)
+	}
+
+	switch comparison.Operator {
+	case "IN", "NOT IN", "INCLUDES", "EXCLUDES":
+		comparison.Right, err = p.listValue(comparison.Operator)
+	default:
+		comparison.Right, err = p.literal()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return comparison, nil
+}
+
+// listValue parses the right-hand side of IN, NOT IN, INCLUDES and EXCLUDES: a list of
+// values, a semi-join subquery or a bind variable
+func (p *parser) listValue(operator string) (Value, error) {
+	token := p.peek()
+	switch {
+	case token.Kind == TokenColon:
+		return p.bindVariable()
+	case token.Kind != TokenLParen:
+		return nil, p.errorf(token, "expected '(' after %s, found %s", operator, token)
+	case p.peekAt(1).is("SELECT"):
+		if operator != "IN" && operator != "NOT IN" {
+			return nil, p.errorf(p.peekAt(1), "%s does not accept a subquery", operator)
+		}
+		return p.subquery()
+	}
+
+	p.next()
+	list := &ListValue{StartPos: token.Pos}
+	for {
+		value, err := p.literal()
+		if err != nil {
+			return nil, err
+		}
+		list.Values = append(list.Values, value)
+		if !p.acceptKind(TokenComma) {
+			break
+		}
+	}
+	if _, err := p.expectKind(TokenRParen); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// literal parses a single value: a string, number, date, boolean, null, date literal,
+// currency or bind variable
+func (p *parser) literal() (Value, error) {
+	token := p.peek()
+	switch token.Kind {
+	case TokenString:
+		p.next()
+		return &StringLiteral{Raw: token.Text, StartPos: token.Pos}, nil
+	case TokenNumber:
+		p.next()
+		return &NumberLiteral{Raw: token.Text, StartPos: token.Pos}, nil
+	case TokenDate:
+		p.next()
+		return &DateTimeLiteral{Raw: token.Text, StartPos: token.Pos}, nil
+	case TokenColon:
+		return p.bindVariable()
+	case TokenIdent:
+		name := strings.ToUpper(token.Text)
+		switch {
+		case name == "TRUE" || name == "FALSE":
+			p.next()
+			return &BooleanLiteral{Value: name == "TRUE", StartPos: token.Pos}, nil
+		case name == "NULL":
+			p.next()
+			return &NullLiteral{StartPos: token.Pos}, nil
+		case dateLiterals[name]:
+			p.next()
+			return &DateLiteral{Name: name, StartPos: token.Pos}, nil
+		case dateLiteralN.MatchString(name):
+			p.next()
+			if _, err := p.expectKind(TokenColon); err != nil {
+				return nil, err
+			}
+			number := p.next()
+			n, err := strconv.Atoi(number.Text)
+			if number.Kind != TokenNumber || err != nil || n < 0 {
+				return nil, p.errorf(number, "expected a non-negative integer after %s:, found %s", name, number)
+			}
+			return &DateLiteral{Name: name, N: &n, StartPos: token.Pos}, nil
+		case isCurrencyLiteral(token.Text):
+			p.next()
+			return &CurrencyLiteral{Raw: token.Text, StartPos: token.Pos}, nil
+		}
+	}
+	return nil, p.errorf(token, "expected a value, found %s", token)
+}
+
+// bindVariable parses an Apex bind variable such as :accountIds or :account.Id
+func (p *parser) bindVariable() (*BindVariable, error) {
+	colon := p.next()
+	name, err := p.expectKind(TokenIdent)
+	if err != nil {
+		return nil, err
+	}
+	bind := &BindVariable{Name: name.Text, StartPos: colon.Pos}
+	for p.peek().Kind == TokenDot && p.peekAt(1).Kind == TokenIdent {
+		p.next()
+		bind.Name += "." + p.next().Text
+	}
+	return bind, nil
+}
@@ -0,0 +1,183 @@
+package soql
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseNames(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		object string
+		fields []string
+	}{
+		{name: "Order object", query: "SELECT Id FROM Order", object: "Order", fields: []string{"Id"}},
+		{name: "Group object", query: "SELECT Id FROM Group", object: "Group", fields: []string{"Id"}},
+		{name: "Order relationship", query: "SELECT Order.OrderNumber FROM OrderItem", object: "OrderItem", fields: []string{"Order.OrderNumber"}},
+		{name: "Group field", query: "SELECT Id, Group FROM Foo__c", object: "Foo__c", fields: []string{"Id", "Group"}},
+		{name: "keyword field named From", query: "SELECT Id, From FROM Foo__c", object: "Foo__c", fields: []string{"Id", "From"}},
+		{name: "object alias", query: "SELECT a.Name FROM Account a WHERE a.Name != null", object: "Account", fields: []string{"a.Name"}},
+		{name: "keyword object without alias", query: "SELECT Id FROM Order WHERE Status = 'Draft'", object: "Order", fields: []string{"Id"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			query, err := Parse(test.query)
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", test.query, err)
+			}
+			if query.From.Name != test.object {
+				t.Errorf("From.Name = %q, want %q", query.From.Name, test.object)
+			}
+			var fields []string
+			for _, item := range query.Select {
+				fields = append(fields, item.String())
+			}
+			if !reflect.DeepEqual(fields, test.fields) {
+				t.Errorf("Select = %q, want %q", fields, test.fields)
+			}
+		})
+	}
+}
+
+func TestParsePositions(t *testing.T) {
+	query, err := Parse("SELECT Id,\n  Account.Name FROM Contact")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	field := query.Select[1].(*SelectExpr).Expr.(*FieldRef)
+	want := []Position{{Offset: 13, Line: 2, Column: 3}, {Offset: 21, Line: 2, Column: 11}}
+	if !reflect.DeepEqual(field.PathPos, want) {
+		t.Errorf("PathPos = %+v, want %+v", field.PathPos, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{name: "not a select", query: "UPDATE Account", want: `line 1:1: expected SELECT, found "UPDATE"`},
+		{name: "empty select list", query: "SELECT FROM Account", want: `line 1:8: expected field name, found "FROM"`},
+		{name: "trailing comma", query: "SELECT Id, FROM Account", want: `line 1:12: expected field name, found "FROM"`},
+		{name: "missing object", query: "SELECT Id FROM", want: "line 1:15: expected object name, found end of query"},
+		{name: "empty where", query: "SELECT Id FROM Account WHERE", want: "line 1:29: expected field name, found end of query"},
+		{name: "missing operator", query: "SELECT Id FROM Account WHERE Name", want: "line 1:34: expected a comparison operator, found end of query"},
+		{name: "mixed and or", query: "SELECT Id FROM Account WHERE Name = 'x' AND Type = 'y' OR Industry = 'z'", want: "line 1:56: AND and OR cannot be mixed without parentheses"},
+		{name: "negative limit", query: "SELECT Id FROM Account LIMIT -1", want: "line 1:30: LIMIT must be a non-negative integer, found -1"},
+		{name: "limit not a number", query: "SELECT Id FROM Account LIMIT abc", want: `line 1:30: expected a number after LIMIT, found "abc"`},
+		{name: "trailing tokens", query: "SELECT Id FROM Account ORDER BY Name extra", want: `line 1:38: unexpected "extra" after end of query`},
+		{name: "includes subquery", query: "SELECT Id FROM Account WHERE Tags__c INCLUDES (SELECT Id FROM Tag)", want: "line 1:48: INCLUDES does not accept a subquery"},
+		{name: "typeof without when", query: "SELECT TYPEOF What END FROM Event", want: `line 1:20: expected WHEN, found "END"`},
+		{name: "unknown with", query: "SELECT Id FROM Account WITH FOO", want: `line 1:29: expected SECURITY_ENFORCED or USER_MODE or SYSTEM_MODE or DATA CATEGORY, found "FOO"`},
+		{name: "unterminated subquery", query: "SELECT Id, (SELECT Id FROM Contacts FROM Account", want: `line 1:37: expected ')', found "FROM"`},
+		{name: "lexer error", query: "SELECT Id FROM Account WHERE Name # 'x'", want: "line 1:35: unexpected character '#'"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Parse(test.query)
+			if err == nil {
+				t.Fatalf("Parse(%q) succeeded, want error %q", test.query, test.want)
+			}
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("Parse(%q) returned %T, want *ParseError", test.query, err)
+			}
+			if err.Error() != test.want {
+				t.Errorf("Parse(%q) error = %q, want %q", test.query, err.Error(), test.want)
+			}
+		})
+	}
+}
+
+func TestParseSearch(t *testing.T) {
+	tests := []struct {
+		name      string
+		search    string
+		prefix    string
+		in        string
+		returning []string
+		suffix    string
+	}{
+		{
+			name:   "search term only",
+			search: "FIND {Acme}",
+			prefix: "FIND {Acme}",
+		},
+		{
+			name:   "clauses without returning",
+			search: "FIND {Acme} IN NAME FIELDS LIMIT 10",
+			prefix: "FIND {Acme} IN NAME FIELDS",
+			in:     "NAME",
+			suffix: "LIMIT 10",
+		},
+		{
+			name:      "returning objects",
+			search:    "find {Acme\\} Corp} in all fields returning Account(name where industry = 'Tech' order by name limit 5), Contact, Order(OrderNumber) with division = 'Global' limit 20",
+			prefix:    "find {Acme\\} Corp} in all fields",
+			in:        "ALL",
+			returning: []string{"Account(name WHERE industry = 'Tech' ORDER BY name LIMIT 5)", "Contact", "Order(OrderNumber)"},
+			suffix:    "with division = 'Global' limit 20",
+		},
+		{
+			name:      "list view and function alias",
+			search:    "FIND 'Acme' RETURNING Opportunity(toLabel(StageName) stage USING ListView = Recent OFFSET 10)",
+			prefix:    "FIND 'Acme'",
+			returning: []string{"Opportunity(toLabel(StageName) stage USING ListView = Recent OFFSET 10)"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			search, err := ParseSearch(test.search)
+			if err != nil {
+				t.Fatalf("ParseSearch(%q) failed: %v", test.search, err)
+			}
+			var returning []string
+			for _, object := range search.Returning {
+				returning = append(returning, object.String())
+			}
+			if search.Prefix != test.prefix || search.Suffix != test.suffix {
+				t.Errorf("Prefix, Suffix = %q, %q, want %q, %q", search.Prefix, search.Suffix, test.prefix, test.suffix)
+			}
+			if search.In != test.in {
+				t.Errorf("In = %q, want %q", search.In, test.in)
+			}
+			if !reflect.DeepEqual(returning, test.returning) {
+				t.Errorf("Returning = %q, want %q", returning, test.returning)
+			}
+		})
+	}
+}
+
+func TestParseSearchErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		search string
+		want   string
+	}{
+		{name: "not a search", search: "SELECT Id FROM Account", want: `line 1:1: expected FIND, found "SELECT"`},
+		{name: "missing term", search: "FIND RETURNING Account", want: `line 1:6: expected search term, found "RETURNING"`},
+		{name: "unterminated term", search: "FIND {Acme", want: "line 1:6: unterminated search term"},
+		{name: "empty field list", search: "FIND {Acme} RETURNING Account()", want: `line 1:31: expected field name, found ")"`},
+		{name: "unclosed field list", search: "FIND {Acme} RETURNING Account(Name", want: "line 1:35: expected ')', found end of query"},
+		{name: "second returning", search: "FIND {Acme} RETURNING Account WITH DIVISION = 'x' RETURNING Contact", want: "line 1:51: RETURNING must directly follow the search term and IN clause"},
+		{name: "returning after other clauses", search: "FIND {Acme} LIMIT 5 RETURNING Contact(SSN__c)", want: "line 1:21: RETURNING must directly follow the search term and IN clause"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseSearch(test.search)
+			if err == nil {
+				t.Fatalf("ParseSearch(%q) succeeded, want error %q", test.search, test.want)
+			}
+			if err.Error() != test.want {
+				t.Errorf("ParseSearch(%q) error = %q, want %q", test.search, err.Error(), test.want)
+			}
+		})
+	}
+}
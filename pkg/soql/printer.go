@@ -0,0 +1,265 @@
+package soql
+
+import (
+	"strconv"
+	"strings"
+)
+
+// functionNames maps lower case function names to their canonical spelling. Functions
+// that are not listed keep the spelling of the query.
+var functionNames = map[string]string{
+	"avg": "AVG", "count": "COUNT", "count_distinct": "COUNT_DISTINCT", "min": "MIN",
+	"max": "MAX", "sum": "SUM", "grouping": "GROUPING", "format": "FORMAT", "fields": "FIELDS",
+	"tolabel": "toLabel", "convertcurrency": "convertCurrency", "converttimezone": "convertTimezone",
+	"distance": "DISTANCE", "geolocation": "GEOLOCATION",
+	"calendar_month": "CALENDAR_MONTH", "calendar_quarter": "CALENDAR_QUARTER",
+	"calendar_year": "CALENDAR_YEAR", "day_in_month": "DAY_IN_MONTH", "day_in_week": "DAY_IN_WEEK",
+	"day_in_year": "DAY_IN_YEAR", "day_only": "DAY_ONLY", "fiscal_month": "FISCAL_MONTH",
+	"fiscal_quarter": "FISCAL_QUARTER", "fiscal_year": "FISCAL_YEAR", "hour_in_day": "HOUR_IN_DAY",
+	"week_in_month": "WEEK_IN_MONTH", "week_in_year": "WEEK_IN_YEAR",
+}
+
+// String returns the query as canonical SOQL: keywords in upper case, one space between
+// tokens, ", " between list items and nested AND/OR conditions in parentheses
+func (q *Query) String() string {
+	var buffer strings.Builder
+	if q.Subquery {
+		buffer.WriteString("(")
+	}
+
+	buffer.WriteString("SELECT ")
+	buffer.WriteString(join(q.Select))
+	buffer.WriteString(" FROM ")
+	buffer.WriteString(q.From.String())
+	if q.Scope != "" {
+		buffer.WriteString(" USING SCOPE " + q.Scope)
+	}
+	if q.Where != nil {
+		buffer.WriteString(" WHERE " + q.Where.String())
+	}
+	if q.With != nil {
+		buffer.WriteString(" " + q.With.String())
+	}
+	if q.GroupBy != nil {
+		buffer.WriteString(" " + q.GroupBy.String())
+	}
+	if q.Having != nil {
+		buffer.WriteString(" HAVING " + q.Having.String())
+	}
+	if len(q.OrderBy) > 0 {
+		buffer.WriteString(" ORDER BY " + join(q.OrderBy))
+	}
+	if q.Limit != nil {
+		buffer.WriteString(" LIMIT " + q.Limit.String())
+	}
+	if q.Offset != nil {
+		buffer.WriteString(" OFFSET " + q.Offset.String())
+	}
+	// FOR VIEW and FOR REFERENCE precede UPDATE TRACKING/VIEWSTAT, which precede FOR UPDATE
+	forUpdate := false
+	for _, option := range q.For {
+		if option == "UPDATE" {
+			forUpdate = true
+			continue
+		}
+		buffer.WriteString(" FOR " + option)
+	}
+	for _, option := range q.Update {
+		buffer.WriteString(" UPDATE " + option)
+	}
+	if forUpdate {
+		buffer.WriteString(" FOR UPDATE")
+	}
+
+	if q.Subquery {
+		buffer.WriteString(")")
+	}
+	return buffer.String()
+}
+
+// String returns the search with its RETURNING clause as canonical SOSL
+func (s *Search) String() string {
+	parts := []string{s.Prefix}
+	if len(s.Returning) > 0 {
+		parts = append(parts, "RETURNING "+join(s.Returning))
+	}
+	if s.Suffix != "" {
+		parts = append(parts, s.Suffix)
+	}
+	return strings.Join(parts, " ")
+}
+
+func (r *ReturningObject) String() string {
+	if len(r.Fields) == 0 {
+		return r.Object.String()
+	}
+
+	var buffer strings.Builder
+	buffer.WriteString(r.Object.String() + "(" + join(r.Fields))
+	if r.ListView != "" {
+		buffer.WriteString(" USING ListView = " + r.ListView)
+	}
+	if r.Where != nil {
+		buffer.WriteString(" WHERE " + r.Where.String())
+	}
+	if len(r.OrderBy) > 0 {
+		buffer.WriteString(" ORDER BY " + join(r.OrderBy))
+	}
+	if r.Limit != nil {
+		buffer.WriteString(" LIMIT " + r.Limit.String())
+	}
+	if r.Offset != nil {
+		buffer.WriteString(" OFFSET " + r.Offset.String())
+	}
+	buffer.WriteString(")")
+	return buffer.String()
+}
+
+func (o *ObjectRef) String() string {
+	if o.Alias != "" {
+		return o.Name + " " + o.Alias
+	}
+	return o.Name
+}
+
+func (s *SelectExpr) String() string {
+	if s.Alias != "" {
+		return s.Expr.String() + " " + s.Alias
+	}
+	return s.Expr.String()
+}
+
+func (t *TypeOf) String() string {
+	var buffer strings.Builder
+	buffer.WriteString("TYPEOF " + t.Relationship)
+	for _, when := range t.Whens {
+		buffer.WriteString(" " + when.String())
+	}
+	if len(t.Else) > 0 {
+		buffer.WriteString(" ELSE " + join(t.Else))
+	}
+	buffer.WriteString(" END")
+	return buffer.String()
+}
+
+func (w *TypeOfWhen) String() string {
+	return "WHEN " + w.Type + " THEN " + join(w.Fields)
+}
+
+func (f *FieldRef) String() string {
+	return f.Name()
+}
+
+func (f *FunctionCall) String() string {
+	name := f.Name
+	if canonical, ok := functionNames[strings.ToLower(name)]; ok {
+		name = canonical
+	}
+
+	args := make([]string, len(f.Args))
+	for i, arg := range f.Args {
+		args[i] = arg.String()
+		// FIELDS takes ALL, STANDARD or CUSTOM rather than a field
+		if name == "FIELDS" {
+			args[i] = strings.ToUpper(args[i])
+		}
+	}
+	return name + "(" + strings.Join(args, ", ") + ")"
+}
+
+func (w *WithClause) String() string {
+	if len(w.DataCategories) == 0 {
+		return "WITH " + w.Kind
+	}
+	filters := make([]string, len(w.DataCategories))
+	for i, filter := range w.DataCategories {
+		filters[i] = filter.String()
+	}
+	return "WITH " + w.Kind + " " + strings.Join(filters, " AND ")
+}
+
+func (d *DataCategoryFilter) String() string {
+	if len(d.Categories) == 1 {
+		return d.Group + " " + d.Selector + " " + d.Categories[0]
+	}
+	return d.Group + " " + d.Selector + " (" + strings.Join(d.Categories, ", ") + ")"
+}
+
+func (g *GroupBy) String() string {
+	if g.Kind != "" {
+		return "GROUP BY " + g.Kind + "(" + join(g.Exprs) + ")"
+	}
+	return "GROUP BY " + join(g.Exprs)
+}
+
+func (o *OrderItem) String() string {
+	text := o.Expr.String()
+	if o.Direction != "" {
+		text += " " + o.Direction
+	}
+	if o.Nulls != "" {
+		text += " NULLS " + o.Nulls
+	}
+	return text
+}
+
+func (c *Comparison) String() string {
+	return c.Left.String() + " " + c.Operator + " " + c.Right.String()
+}
+
+func (l *LogicalCondition) String() string {
+	conditions := make([]string, len(l.Conditions))
+	for i, condition := range l.Conditions {
+		conditions[i] = nestedCondition(condition)
+	}
+	return strings.Join(conditions, " "+l.Operator+" ")
+}
+
+func (n *NotCondition) String() string {
+	return "NOT " + nestedCondition(n.Condition)
+}
+
+// nestedCondition wraps AND/OR conditions in parentheses
+func nestedCondition(condition Condition) string {
+	if _, ok := condition.(*LogicalCondition); ok {
+		return "(" + condition.String() + ")"
+	}
+	return condition.String()
+}
+
+func (v *StringLiteral) String() string { return "'" + v.Raw + "'" }
+
+func (v *NumberLiteral) String() string { return v.Raw }
+
+func (v *BooleanLiteral) String() string {
+	if v.Value {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+func (v *NullLiteral) String() string { return "NULL" }
+
+func (v *DateTimeLiteral) String() string { return v.Raw }
+
+func (v *DateLiteral) String() string {
+	if v.N != nil {
+		return v.Name + ":" + strconv.Itoa(*v.N)
+	}
+	return v.Name
+}
+
+func (v *CurrencyLiteral) String() string { return strings.ToUpper(v.Raw[:3]) + v.Raw[3:] }
+
+func (v *BindVariable) String() string { return ":" + v.Name }
+
+func (v *ListValue) String() string { return "(" + join(v.Values) + ")" }
+
+// join prints nodes separated by ", "
+func join[T Node](nodes []T) string {
+	parts := make([]string, len(nodes))
+	for i, node := range nodes {
+		parts[i] = node.String()
+	}
+	return strings.Join(parts, ", ")
+}
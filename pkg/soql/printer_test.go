@@ -0,0 +1,134 @@
+package soql
+
+import "testing"
+
+func TestQueryStringRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "keywords and operators are normalized",
+			query: "select id, name from account where name like 'A%' and (industry = 'Tech' or industry = null) order by name desc nulls last limit 10 offset 5",
+			want:  "SELECT id, name FROM account WHERE name LIKE 'A%' AND (industry = 'Tech' OR industry = NULL) ORDER BY name DESC NULLS LAST LIMIT 10 OFFSET 5",
+		},
+		{
+			name:  "keyword object names",
+			query: "SELECT Id FROM Order ORDER BY OrderNumber",
+			want:  "SELECT Id FROM Order ORDER BY OrderNumber",
+		},
+		{
+			name:  "keyword relationship and field names",
+			query: "SELECT Order.OrderNumber, Group FROM OrderItem",
+			want:  "SELECT Order.OrderNumber, Group FROM OrderItem",
+		},
+		{
+			name:  "aggregates with aliases and rollup",
+			query: "SELECT count(Id) cnt, calendar_year(CreatedDate) FROM Opportunity GROUP BY ROLLUP(calendar_year(CreatedDate)) HAVING count(Id) > 1",
+			want:  "SELECT COUNT(Id) cnt, CALENDAR_YEAR(CreatedDate) FROM Opportunity GROUP BY ROLLUP(CALENDAR_YEAR(CreatedDate)) HAVING COUNT(Id) > 1",
+		},
+		{
+			name:  "subqueries and lists",
+			query: "SELECT Id, (SELECT Id FROM Contacts) FROM Account WHERE Id IN (SELECT AccountId FROM Contact) AND Type NOT IN ('a','b')",
+			want:  "SELECT Id, (SELECT Id FROM Contacts) FROM Account WHERE Id IN (SELECT AccountId FROM Contact) AND Type NOT IN ('a', 'b')",
+		},
+		{
+			name:  "typeof",
+			query: "SELECT TYPEOF What WHEN Account THEN Phone WHEN Opportunity THEN Amount ELSE Name END FROM Event",
+			want:  "SELECT TYPEOF What WHEN Account THEN Phone WHEN Opportunity THEN Amount ELSE Name END FROM Event",
+		},
+		{
+			name:  "date, currency and datetime literals",
+			query: "SELECT Id FROM Account WHERE CreatedDate = last_n_days:30 AND AnnualRevenue > USD5000 AND LastModifiedDate < 2024-01-31T00:00:00Z",
+			want:  "SELECT Id FROM Account WHERE CreatedDate = LAST_N_DAYS:30 AND AnnualRevenue > USD5000 AND LastModifiedDate < 2024-01-31T00:00:00Z",
+		},
+		{
+			name:  "with and for clauses",
+			query: "SELECT Id FROM Account WHERE NOT Name = 'x' WITH SECURITY_ENFORCED FOR UPDATE UPDATE TRACKING FOR VIEW",
+			want:  "SELECT Id FROM Account WHERE NOT Name = 'x' WITH SECURITY_ENFORCED FOR VIEW UPDATE TRACKING FOR UPDATE",
+		},
+		{
+			name:  "data categories",
+			query: "SELECT Title FROM KnowledgeArticleVersion WITH DATA CATEGORY Geography__c AT (usa__c, uk__c) AND Product__c BELOW all__c",
+			want:  "SELECT Title FROM KnowledgeArticleVersion WITH DATA CATEGORY Geography__c AT (usa__c, uk__c) AND Product__c BELOW all__c",
+		},
+		{
+			name:  "alias, scope and bind variables",
+			query: "SELECT Id FROM Account a USING SCOPE mine WHERE Name = :name LIMIT :n",
+			want:  "SELECT Id FROM Account a USING SCOPE mine WHERE Name = :name LIMIT :n",
+		},
+		{
+			name:  "escapes are kept and <> becomes !=",
+			query: `SELECT Id FROM Account WHERE Name = 'caf\u00e9 \'x\'' AND Id <> '001'`,
+			want:  `SELECT Id FROM Account WHERE Name = 'caf\u00e9 \'x\'' AND Id != '001'`,
+		},
+		{
+			name:  "fields arguments are upper cased",
+			query: "SELECT fields(all) FROM Account LIMIT 200",
+			want:  "SELECT FIELDS(ALL) FROM Account LIMIT 200",
+		},
+		{
+			name:  "nested not",
+			query: "SELECT Id FROM Account WHERE NOT (Name = 'a' OR Name = 'b')",
+			want:  "SELECT Id FROM Account WHERE NOT (Name = 'a' OR Name = 'b')",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			query, err := Parse(test.query)
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", test.query, err)
+			}
+			if got := query.String(); got != test.want {
+				t.Fatalf("String() = %q, want %q", got, test.want)
+			}
+
+			// Canonical SOQL parses back to itself
+			reparsed, err := Parse(test.want)
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", test.want, err)
+			}
+			if got := reparsed.String(); got != test.want {
+				t.Fatalf("round trip String() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSearchStringRoundTrip(t *testing.T) {
+	tests := []struct {
+		search string
+		want   string
+	}{
+		{search: "FIND {Acme}", want: "FIND {Acme}"},
+		{
+			search: "FIND {Acme} IN NAME FIELDS RETURNING Account(Id,Name where Industry='Tech'), Contact LIMIT 20",
+			want:   "FIND {Acme} IN NAME FIELDS RETURNING Account(Id, Name WHERE Industry = 'Tech'), Contact LIMIT 20",
+		},
+		{
+			search: "FIND {Acme} RETURNING Opportunity(Name USING ListView=Recent ORDER BY CloseDate DESC LIMIT 5 OFFSET 5)",
+			want:   "FIND {Acme} RETURNING Opportunity(Name USING ListView = Recent ORDER BY CloseDate DESC LIMIT 5 OFFSET 5)",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.search, func(t *testing.T) {
+			search, err := ParseSearch(test.search)
+			if err != nil {
+				t.Fatalf("ParseSearch(%q) failed: %v", test.search, err)
+			}
+			if got := search.String(); got != test.want {
+				t.Fatalf("String() = %q, want %q", got, test.want)
+			}
+			reparsed, err := ParseSearch(test.want)
+			if err != nil {
+				t.Fatalf("ParseSearch(%q) failed: %v", test.want, err)
+			}
+			if got := reparsed.String(); got != test.want {
+				t.Fatalf("round trip String() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
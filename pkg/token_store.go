@@ -0,0 +1,87 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// StoredToken holds the long-lived credentials persisted by `soql-mcp login`
+type StoredToken struct {
+	LoginURL     string    `json:"loginUrl"`
+	InstanceURL  string    `json:"instanceUrl"`
+	ClientID     string    `json:"clientId"`
+	RefreshToken string    `json:"refreshToken"`
+	IssuedAt     time.Time `json:"issuedAt"`
+}
+
+var tokenStoreMutex sync.Mutex
+
+// DefaultTokenFile returns the default location of the token store
+func DefaultTokenFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "soql-mcp", "tokens.json")
+}
+
+// LoadStoredToken returns the stored token for an org profile, or nil if none has been saved
+func LoadStoredToken(path, key string) (*StoredToken, error) {
+	tokenStoreMutex.Lock()
+	defer tokenStoreMutex.Unlock()
+
+	tokens, err := readTokenStore(path)
+	if err != nil {
+		return nil, err
+	}
+	token, ok := tokens[key]
+	if !ok {
+		return nil, nil
+	}
+	return token, nil
+}
+
+// SaveStoredToken persists the token for an org profile, keeping other entries intact
+func SaveStoredToken(path, key string, token *StoredToken) error {
+	tokenStoreMutex.Lock()
+	defer tokenStoreMutex.Unlock()
+
+	tokens, err := readTokenStore(path)
+	if err != nil {
+		return err
+	}
+	tokens[key] = token
+
+	data, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode token store: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create token store directory: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write token store: %v", err)
+	}
+	return nil
+}
+
+// readTokenStore reads every stored token, treating a missing file as empty
+func readTokenStore(path string) (map[string]*StoredToken, error) {
+	tokens := map[string]*StoredToken{}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return tokens, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token store: %v", err)
+	}
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, fmt.Errorf("failed to parse token store %s: %v", path, err)
+	}
+	return tokens, nil
+}
@@ -0,0 +1,112 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/zhongxiao37/soql-mcp/pkg"
+)
+
+// CreateBulkIngestTool creates a new Bulk API 2.0 ingest tool
+func CreateBulkIngestTool() mcp.Tool {
+	return mcp.NewTool("bulk_ingest",
+		mcp.WithDescription("Load a local CSV file into Salesforce with a Bulk API 2.0 ingest job. The CSV header is checked against the object's describe metadata first. Returns the job state and the successful, failed and unprocessed record files."),
+		mcp.WithString("object",
+			mcp.Required(),
+			mcp.Description("The Salesforce object name (e.g., Account)"),
+		),
+		mcp.WithString("csv_path",
+			mcp.Required(),
+			mcp.Description("Local CSV file, relative to or inside the server's bulk directory, whose header row holds field API names, e.g. Id,Name or Account.External_Id__c for lookups"),
+		),
+		mcp.WithString("operation",
+			mcp.Required(),
+			mcp.Description("Ingest operation"),
+			mcp.Enum(pkg.BulkIngestOperations...),
+		),
+		mcp.WithString("external_id_field",
+			mcp.Description("External ID field used to match records (required for upsert)"),
+		),
+		mcp.WithString("results_dir",
+			mcp.Description("Directory for the result CSV files, relative to or inside the server's bulk directory (default: the bulk directory)"),
+		),
+		mcp.WithNumber("preview_rows",
+			mcp.Description(fmt.Sprintf("Number of failed records to include in the response (default: %d)", pkg.DefaultBulkPreviewRows)),
+		),
+		mcp.WithBoolean("dry_run",
+			mcp.Description("Only check the CSV header against the object's describe metadata, without creating a job (default: false)"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format: 'json' or 'table' (default: table)"),
+		),
+		withOrgArgument(),
+	)
+}
+
+// BulkIngestHandler handles Bulk API 2.0 ingest requests
+func BulkIngestHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	objectName, err := request.RequireString("object")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Object parameter is required: %v", err)), nil
+	}
+	csvPath, err := request.RequireString("csv_path")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("CSV path parameter is required: %v", err)), nil
+	}
+	operation, err := request.RequireString("operation")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Operation parameter is required: %v", err)), nil
+	}
+
+	format := request.GetString("format", "table")
+	if format != "json" && format != "table" {
+		format = "table"
+	}
+
+	options := pkg.BulkIngestOptions{
+		Operation:       operation,
+		ExternalIDField: request.GetString("external_id_field", ""),
+		ResultsDir:      request.GetString("results_dir", ""),
+		PreviewRows:     request.GetInt("preview_rows", pkg.DefaultBulkPreviewRows),
+	}
+
+	// Get authenticated Salesforce client for the requested org
+	sfClient, err := getSalesforceClient(request)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Authentication failed: %v", err)), nil
+	}
+
+	// Check the CSV header without creating a job
+	if request.GetBool("dry_run", false) {
+		problems, err := sfClient.ValidateBulkIngest(ctx, objectName, csvPath, options)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Bulk ingest validation failed: %v", err)), nil
+		}
+		if len(problems) > 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("Dry run of %s on %s found %d problem(s):\n- %s",
+				operation, objectName, len(problems), strings.Join(problems, "\n- "))), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Dry run of %s on %s passed validation. No job was created.", operation, objectName)), nil
+	}
+
+	// Run the ingest job
+	result, err := sfClient.BulkIngest(ctx, objectName, csvPath, options)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Bulk ingest failed: %v", err)), nil
+	}
+
+	// Format and return results
+	var output string
+	if format == "json" {
+		output = pkg.FormatBulkIngestAsJSON(result)
+	} else {
+		output = pkg.FormatBulkIngestAsTable(result)
+	}
+
+	if result.State != pkg.BulkStateJobComplete {
+		return mcp.NewToolResultError(output), nil
+	}
+	return mcp.NewToolResultText(output), nil
+}
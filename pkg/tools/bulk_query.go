@@ -0,0 +1,77 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/zhongxiao37/soql-mcp/pkg"
+)
+
+// CreateBulkQueryTool creates a new Bulk API 2.0 query tool
+func CreateBulkQueryTool() mcp.Tool {
+	return mcp.NewTool("bulk_query",
+		mcp.WithDescription("Run a large SOQL extract as a Bulk API 2.0 query job and stream the CSV results to a local file. Returns the job ID, state, record count, file location and a preview of the first rows."),
+		mcp.WithString("soql",
+			mcp.Required(),
+			mcp.Description("The SOQL query to extract (e.g., SELECT Id, Name FROM Account)"),
+		),
+		mcp.WithString("output_path",
+			mcp.Description("New local CSV file to write the results to, relative to or inside the server's bulk directory; an existing file is never overwritten (default: a file named after the job in the bulk directory)"),
+		),
+		mcp.WithBoolean("include_deleted",
+			mcp.Description("Run a queryAll job to include deleted and archived records (default: false)"),
+		),
+		mcp.WithNumber("preview_rows",
+			mcp.Description(fmt.Sprintf("Number of result rows to include in the preview (default: %d)", pkg.DefaultBulkPreviewRows)),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format: 'json' or 'table' (default: table)"),
+		),
+		withOrgArgument(),
+	)
+}
+
+// BulkQueryHandler handles Bulk API 2.0 query requests
+func BulkQueryHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	soql, err := request.RequireString("soql")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Query parameter is required: %v", err)), nil
+	}
+
+	format := request.GetString("format", "table")
+	if format != "json" && format != "table" {
+		format = "table"
+	}
+
+	options := pkg.BulkQueryOptions{
+		IncludeDeleted: request.GetBool("include_deleted", false),
+		OutputPath:     request.GetString("output_path", ""),
+		PreviewRows:    request.GetInt("preview_rows", pkg.DefaultBulkPreviewRows),
+	}
+
+	// Get authenticated Salesforce client for the requested org
+	sfClient, err := getSalesforceClient(request)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Authentication failed: %v", err)), nil
+	}
+
+	// Run the bulk query job
+	result, err := sfClient.BulkQuery(ctx, soql, options)
+	if err != nil {
+		return queryErrorResult("Bulk query failed", err), nil
+	}
+
+	// Format and return results
+	var output string
+	if format == "json" {
+		output = pkg.FormatBulkQueryAsJSON(result)
+	} else {
+		output = pkg.FormatBulkQueryAsTable(result)
+	}
+
+	if result.State != pkg.BulkStateJobComplete {
+		return mcp.NewToolResultError(output), nil
+	}
+	return mcp.NewToolResultText(output), nil
+}
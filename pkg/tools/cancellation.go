@@ -0,0 +1,90 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/zhongxiao37/soql-mcp/pkg"
+)
+
+// requestIDMetaKey carries the JSON-RPC request ID of a tool call from the call hook to the
+// middleware, which only sees the request params
+const requestIDMetaKey = "soql-mcp/requestId"
+
+// Cancellations cancels the context of in-flight tool calls when the client sends
+// notifications/cancelled, which aborts their Salesforce HTTP requests
+type Cancellations struct {
+	calls map[string]context.CancelFunc
+	mutex sync.Mutex
+}
+
+// NewCancellations creates an empty cancellation registry
+func NewCancellations() *Cancellations {
+	return &Cancellations{calls: map[string]context.CancelFunc{}}
+}
+
+// callKey identifies a request within its client session
+func callKey(ctx context.Context, id any) string {
+	sessionID := ""
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		sessionID = session.SessionID()
+	}
+	return fmt.Sprintf("%s/%v", sessionID, id)
+}
+
+// AddHooks records the request ID of every tool call for the middleware
+func (c *Cancellations) AddHooks(hooks *server.Hooks) {
+	hooks.AddBeforeCallTool(func(ctx context.Context, id any, request *mcp.CallToolRequest) {
+		if request.Params.Meta == nil {
+			request.Params.Meta = &mcp.Meta{}
+		}
+		if request.Params.Meta.AdditionalFields == nil {
+			request.Params.Meta.AdditionalFields = map[string]any{}
+		}
+		request.Params.Meta.AdditionalFields[requestIDMetaKey] = id
+	})
+}
+
+// Middleware runs each tool call with a context that HandleCancelled can cancel
+func (c *Cancellations) Middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if request.Params.Meta == nil || request.Params.Meta.AdditionalFields[requestIDMetaKey] == nil {
+			return next(ctx, request)
+		}
+		key := callKey(ctx, request.Params.Meta.AdditionalFields[requestIDMetaKey])
+
+		ctx, cancel := context.WithCancel(ctx)
+		c.mutex.Lock()
+		c.calls[key] = cancel
+		c.mutex.Unlock()
+		defer func() {
+			c.mutex.Lock()
+			delete(c.calls, key)
+			c.mutex.Unlock()
+			cancel()
+		}()
+
+		return next(ctx, request)
+	}
+}
+
+// HandleCancelled handles notifications/cancelled by cancelling the named tool call
+func (c *Cancellations) HandleCancelled(ctx context.Context, notification mcp.JSONRPCNotification) {
+	id, ok := notification.Params.AdditionalFields["requestId"]
+	if !ok {
+		return
+	}
+	key := callKey(ctx, id)
+
+	c.mutex.Lock()
+	cancel, ok := c.calls[key]
+	c.mutex.Unlock()
+	if ok {
+		pkg.LoggerFromContext(ctx).InfoContext(ctx, "tool call cancelled by client",
+			"request_id", id, "reason", notification.Params.AdditionalFields["reason"])
+		cancel()
+	}
+}
@@ -0,0 +1,83 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestCancellationsAbortToolCall(t *testing.T) {
+	started := make(chan struct{})
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-r.Context().Done()
+	}))
+	defer api.Close()
+
+	cancellations := NewCancellations()
+	hooks := &server.Hooks{}
+	cancellations.AddHooks(hooks)
+
+	// A tool whose HTTP request only ends when the call is cancelled
+	handler := cancellations.Middleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		req, err := http.NewRequestWithContext(ctx, "GET", api.URL, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		resp.Body.Close()
+		return mcp.NewToolResultText("finished"), nil
+	})
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "query"
+	for _, hook := range hooks.OnBeforeCallTool {
+		hook(context.Background(), 7, &request)
+	}
+
+	results := make(chan *mcp.CallToolResult, 1)
+	go func() {
+		result, err := handler(context.Background(), request)
+		if err != nil {
+			result = mcp.NewToolResultError(err.Error())
+		}
+		results <- result
+	}()
+	<-started
+
+	// A different request ID leaves the call running
+	notification := mcp.JSONRPCNotification{}
+	notification.Method = "notifications/cancelled"
+	notification.Params.AdditionalFields = map[string]any{"requestId": 8}
+	cancellations.HandleCancelled(context.Background(), notification)
+	select {
+	case <-results:
+		t.Fatal("tool call ended when another request was cancelled")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	notification.Params.AdditionalFields = map[string]any{"requestId": 7, "reason": "user abort"}
+	cancellations.HandleCancelled(context.Background(), notification)
+	select {
+	case result := <-results:
+		if !result.IsError {
+			t.Errorf("got %+v, want an error result", result)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("tool call was not cancelled")
+	}
+
+	cancellations.mutex.Lock()
+	defer cancellations.mutex.Unlock()
+	if len(cancellations.calls) != 0 {
+		t.Errorf("%d calls still registered after the call ended", len(cancellations.calls))
+	}
+}
@@ -0,0 +1,36 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/zhongxiao37/soql-mcp/pkg"
+)
+
+// withOrgArgument adds the optional org argument shared by every Salesforce tool
+func withOrgArgument() mcp.ToolOption {
+	return mcp.WithString("org",
+		mcp.Description("Org profile to use (default: the configured default org; see list_orgs)"),
+	)
+}
+
+// getSalesforceClient returns an authenticated client for the org named in the request
+func getSalesforceClient(request mcp.CallToolRequest) (*pkg.SalesforceClient, error) {
+	// Use the configuration loaded at startup
+	config := pkg.CurrentConfig()
+
+	// Get authenticated Salesforce client (with connection reuse)
+	clientManager := pkg.GetClientManager(config)
+	return clientManager.GetClient(request.GetString("org", ""))
+}
+
+// queryErrorResult returns the tool error for a failed query. Query policy violations are
+// returned as JSON so clients can tell which rule was broken.
+func queryErrorResult(message string, err error) *mcp.CallToolResult {
+	var policyErr *pkg.QueryPolicyError
+	if errors.As(err, &policyErr) {
+		return mcp.NewToolResultError(pkg.FormatQueryPolicyError(policyErr))
+	}
+	return mcp.NewToolResultError(fmt.Sprintf("%s: %v", message, err))
+}
@@ -3,6 +3,7 @@ package tools
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/zhongxiao37/soql-mcp/pkg"
@@ -17,16 +18,35 @@ func CreateDebugTool() mcp.Tool {
 
 // DebugHandler handles debug tool requests and returns configuration information
 func DebugHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	// Load current config
-	config := pkg.LoadConfig()
+	// Use the configuration loaded at startup
+	config := pkg.CurrentConfig()
 
 	// Format config information as a string
 	configInfo := fmt.Sprintf("Server configuration information:\n")
 	configInfo += fmt.Sprintf("  Server name: %s\n", config.ServerName)
 	configInfo += fmt.Sprintf("  Server version: %s\n", config.ServerVersion)
+	configInfo += fmt.Sprintf("  Config file: %s\n", config.ConfigFile)
 	configInfo += fmt.Sprintf("  Resource path: %s\n", config.ResourcePath)
 	configInfo += fmt.Sprintf("  Debug mode: %t\n", config.Debug)
 	configInfo += fmt.Sprintf("  Log level: %s\n", config.LogLevel)
+	configInfo += fmt.Sprintf("  Allow writes: %t\n", config.AllowWrites)
+	configInfo += fmt.Sprintf("  Bulk directory: %s\n", config.BulkDir)
+	configInfo += fmt.Sprintf("  Default org: %s\n", config.DefaultOrg)
+	configInfo += fmt.Sprintf("  Orgs: %s\n", strings.Join(config.OrgNames(), ", "))
+
+	// Describe cache statistics
+	stats := pkg.GetClientManager(config).DescribeCacheStats()
+	configInfo += "Describe cache:\n"
+	if stats.Dir == "" {
+		configInfo += "  Directory: (memory only)\n"
+	} else {
+		configInfo += fmt.Sprintf("  Directory: %s\n", stats.Dir)
+	}
+	configInfo += fmt.Sprintf("  Entries: %d\n", stats.Entries)
+	configInfo += fmt.Sprintf("  Hits (304 Not Modified): %d\n", stats.Hits)
+	configInfo += fmt.Sprintf("  Misses: %d\n", stats.Misses)
+	configInfo += fmt.Sprintf("  Changed since cached: %d\n", stats.Changed)
+	configInfo += fmt.Sprintf("  Forced refreshes: %d\n", stats.Refreshes)
 
 	return mcp.NewToolResultText(configInfo), nil
 }
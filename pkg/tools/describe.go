@@ -19,6 +19,13 @@ func CreateDescribeTool() mcp.Tool {
 		mcp.WithString("format",
 			mcp.Description("Output format: 'json' or 'table' (default: table)"),
 		),
+		mcp.WithString("sections",
+			mcp.Description(fmt.Sprintf("Comma separated sections to include: fields, field_details (relationship, formula, precision and filter/sort/group flags), child_relationships, record_types or all (default: %s)", pkg.DefaultDescribeSections)),
+		),
+		mcp.WithBoolean("refresh",
+			mcp.Description("Bypass the describe cache and download the metadata again (default: false)"),
+		),
+		withOrgArgument(),
 	)
 }
 
@@ -34,18 +41,19 @@ func DescribeHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.Cal
 		format = "table"
 	}
 
-	// Load configuration
-	config := pkg.LoadConfig()
+	sections, err := pkg.ParseDescribeSections(request.GetString("sections", pkg.DefaultDescribeSections))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid sections parameter: %v", err)), nil
+	}
 
-	// Get authenticated Salesforce client (with connection reuse)
-	clientManager := pkg.GetClientManager(config)
-	sfClient, err := clientManager.GetClient()
+	// Get authenticated Salesforce client for the requested org
+	sfClient, err := getSalesforceClient(request)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Authentication failed: %v", err)), nil
 	}
 
 	// Execute describe operation
-	result, err := sfClient.Describe(objectName)
+	result, err := sfClient.Describe(ctx, objectName, request.GetBool("refresh", false))
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Describe operation failed: %v", err)), nil
 	}
@@ -53,9 +61,9 @@ func DescribeHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.Cal
 	// Format and return results
 	var output string
 	if format == "json" {
-		output = pkg.FormatDescribeAsJSON(result)
+		output = pkg.FormatDescribeAsJSON(result, sections)
 	} else {
-		output = pkg.FormatDescribeAsTable(result)
+		output = pkg.FormatDescribeAsTable(result, sections)
 	}
 
 	return mcp.NewToolResultText(output), nil
@@ -0,0 +1,69 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/zhongxiao37/soql-mcp/pkg"
+)
+
+// tableScanWarning is prepended to explain output when no plan can use an index
+const tableScanWarning = "WARNING: every plan is a TableScan. Add a selective filter on an indexed field before running this query against a large object.\n\n"
+
+// CreateExplainTool creates a new SOQL query plan tool
+func CreateExplainTool() mcp.Tool {
+	return mcp.NewTool("explain",
+		mcp.WithDescription("Show the Salesforce query optimizer's plans for a SOQL query without running it"),
+		mcp.WithString("soql",
+			mcp.Required(),
+			mcp.Description("The SOQL query to explain (e.g., SELECT Id FROM Account WHERE Name = 'Acme')"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format: 'json' or 'table' (default: table)"),
+		),
+		mcp.WithBoolean("warn_on_table_scan",
+			mcp.Description("Add a warning when every plan is a TableScan (default: true)"),
+		),
+		withOrgArgument(),
+	)
+}
+
+// ExplainHandler handles SOQL query plan requests
+func ExplainHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	soql, err := request.RequireString("soql")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Query parameter is required: %v", err)), nil
+	}
+
+	format := request.GetString("format", "table")
+	if format != "json" && format != "table" {
+		format = "table"
+	}
+
+	// Get authenticated Salesforce client for the requested org
+	sfClient, err := getSalesforceClient(request)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Authentication failed: %v", err)), nil
+	}
+
+	// Fetch the query plans
+	result, err := sfClient.Explain(ctx, soql)
+	if err != nil {
+		return queryErrorResult("Explain failed", err), nil
+	}
+
+	// Format and return results
+	var output string
+	if format == "json" {
+		output = pkg.FormatExplainAsJSON(result)
+	} else {
+		output = pkg.FormatExplainAsTable(result)
+	}
+
+	if request.GetBool("warn_on_table_scan", true) && result.AllTableScans() {
+		output = tableScanWarning + output
+	}
+
+	return mcp.NewToolResultText(output), nil
+}
@@ -0,0 +1,62 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/zhongxiao37/soql-mcp/pkg"
+)
+
+// CreateLimitsTool creates a new org limits tool
+func CreateLimitsTool() mcp.Tool {
+	return mcp.NewTool("limits",
+		mcp.WithDescription("Show the org's limits, such as daily API requests and data storage, with max and remaining values"),
+		mcp.WithString("filter",
+			mcp.Description("Comma separated limit names to show, matched as case-insensitive substrings (e.g., DailyApiRequests,DataStorageMB)"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format: 'json' or 'table' (default: table)"),
+		),
+		withOrgArgument(),
+	)
+}
+
+// LimitsHandler handles org limits requests
+func LimitsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	format := request.GetString("format", "table")
+	if format != "json" && format != "table" {
+		format = "table"
+	}
+
+	var names []string
+	for _, name := range strings.Split(request.GetString("filter", ""), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	// Get authenticated Salesforce client for the requested org
+	sfClient, err := getSalesforceClient(request)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Authentication failed: %v", err)), nil
+	}
+
+	// Fetch org limits
+	limits, err := sfClient.Limits(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Limits request failed: %v", err)), nil
+	}
+	limits = limits.Filter(names)
+
+	// Format and return results
+	var output string
+	if format == "json" {
+		output = pkg.FormatLimitsAsJSON(limits)
+	} else {
+		output = pkg.FormatLimitsAsTable(limits)
+	}
+
+	return mcp.NewToolResultText(output), nil
+}
@@ -0,0 +1,66 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/zhongxiao37/soql-mcp/pkg"
+)
+
+// CreateListObjectsTool creates a new tool that lists the org's sObjects
+func CreateListObjectsTool() mcp.Tool {
+	return mcp.NewTool("list_objects",
+		mcp.WithDescription("List the Salesforce objects available in the org with their label, key prefix and custom, queryable, createable and searchable flags"),
+		mcp.WithString("search",
+			mcp.Description("Only include objects whose API name or label contains this text (case-insensitive)"),
+		),
+		mcp.WithBoolean("custom_only",
+			mcp.Description("Only include custom objects (default: false)"),
+		),
+		mcp.WithBoolean("queryable_only",
+			mcp.Description("Only include queryable objects (default: false)"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format: 'json' or 'table' (default: table)"),
+		),
+		withOrgArgument(),
+	)
+}
+
+// ListObjectsHandler handles list_objects requests
+func ListObjectsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	format := request.GetString("format", "table")
+	if format != "json" && format != "table" {
+		format = "table"
+	}
+
+	filter := pkg.SObjectFilter{
+		CustomOnly:    request.GetBool("custom_only", false),
+		QueryableOnly: request.GetBool("queryable_only", false),
+		Search:        request.GetString("search", ""),
+	}
+
+	// Get authenticated Salesforce client for the requested org
+	sfClient, err := getSalesforceClient(request)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Authentication failed: %v", err)), nil
+	}
+
+	// Execute describeGlobal
+	result, err := sfClient.DescribeGlobal(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Describe global operation failed: %v", err)), nil
+	}
+	sobjects := result.Filter(filter)
+
+	// Format and return results
+	var output string
+	if format == "json" {
+		output = pkg.FormatSObjectsAsJSON(sobjects)
+	} else {
+		output = pkg.FormatSObjectsAsTable(sobjects)
+	}
+
+	return mcp.NewToolResultText(output), nil
+}
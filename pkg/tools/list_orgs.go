@@ -0,0 +1,60 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/zhongxiao37/soql-mcp/pkg"
+)
+
+// CreateListOrgsTool creates a tool that lists the configured org profiles
+func CreateListOrgsTool() mcp.Tool {
+	return mcp.NewTool("list_orgs",
+		mcp.WithDescription("List the configured Salesforce org profiles and their connection state"),
+		mcp.WithString("format",
+			mcp.Description("Output format: 'json' or 'table' (default: table)"),
+		),
+	)
+}
+
+// ListOrgsHandler handles list_orgs requests
+func ListOrgsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	format := request.GetString("format", "table")
+	if format != "json" && format != "table" {
+		format = "table"
+	}
+
+	// Use the configuration loaded at startup
+	config := pkg.CurrentConfig()
+	statuses := pkg.GetClientManager(config).ListOrgs()
+
+	if format == "json" {
+		jsonBytes, _ := json.MarshalIndent(statuses, "", "  ")
+		return mcp.NewToolResultText(string(jsonBytes)), nil
+	}
+
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("%-15s %-8s %-10s %-10s %-40s %s\n",
+		"Org", "Default", "Auth Flow", "Connected", "URL", "Username"))
+	buffer.WriteString(strings.Repeat("-", 100) + "\n")
+	for _, status := range statuses {
+		url := status.URL
+		if status.InstanceURL != "" {
+			url = status.InstanceURL
+		}
+		buffer.WriteString(fmt.Sprintf("%-15s %-8t %-10s %-10t %-40s %s\n",
+			status.Name, status.Default, status.AuthFlow, status.Connected, url, status.Username))
+		if status.QueryPolicy != nil {
+			buffer.WriteString(fmt.Sprintf("  Query policy: %s\n", status.QueryPolicy))
+		}
+		if status.LastError != "" {
+			buffer.WriteString(fmt.Sprintf("  Last error: %s\n", status.LastError))
+		}
+	}
+
+	return mcp.NewToolResultText(buffer.String()), nil
+}
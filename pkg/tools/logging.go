@@ -0,0 +1,47 @@
+package tools
+
+import (
+	"context"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/zhongxiao37/soql-mcp/pkg"
+)
+
+// LoggingMiddleware logs every tool call with its tool name, org and duration, and passes
+// a logger carrying the tool and org fields to the handler through the context
+func LoggingMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		org := request.GetString("org", "")
+		if org == "" {
+			org = pkg.CurrentConfig().DefaultOrg
+		}
+		logger := pkg.Logger().With("tool", request.Params.Name, "org", org)
+		ctx = pkg.WithLogger(ctx, logger)
+
+		start := time.Now()
+		result, err := next(ctx, request)
+		duration := time.Since(start)
+
+		switch {
+		case err != nil:
+			logger.ErrorContext(ctx, "tool call failed", "duration", duration, "error", err)
+		case result != nil && result.IsError:
+			logger.WarnContext(ctx, "tool call returned an error", "duration", duration, "error", toolResultText(result))
+		default:
+			logger.InfoContext(ctx, "tool call completed", "duration", duration)
+		}
+		return result, err
+	}
+}
+
+// toolResultText returns the text content of a tool result
+func toolResultText(result *mcp.CallToolResult) string {
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			return text.Text
+		}
+	}
+	return ""
+}
@@ -0,0 +1,39 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/zhongxiao37/soql-mcp/pkg"
+)
+
+// ProgressMiddleware sends notifications/progress for tool calls that carry a progress
+// token, e.g. records fetched out of totalSize while a query pages through results
+func ProgressMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		srv := server.ServerFromContext(ctx)
+		if srv == nil || request.Params.Meta == nil || request.Params.Meta.ProgressToken == nil {
+			return next(ctx, request)
+		}
+
+		token := request.Params.Meta.ProgressToken
+		notifyCtx := ctx
+		ctx = pkg.WithProgress(ctx, func(progress, total float64, message string) {
+			params := map[string]any{
+				"progressToken": token,
+				"progress":      progress,
+			}
+			if total > 0 {
+				params["total"] = total
+			}
+			if message != "" {
+				params["message"] = message
+			}
+			if err := srv.SendNotificationToClient(notifyCtx, "notifications/progress", params); err != nil {
+				pkg.LoggerFromContext(notifyCtx).Debug("failed to send progress notification", "error", err)
+			}
+		})
+		return next(ctx, request)
+	}
+}
@@ -19,6 +19,16 @@ func CreateQueryTool() mcp.Tool {
 		mcp.WithString("format",
 			mcp.Description("Output format: 'json' or 'table' (default: json)"),
 		),
+		mcp.WithNumber("max_records",
+			mcp.Description(fmt.Sprintf("Maximum number of records to fetch across result pages (default: %d)", pkg.DefaultMaxRecords)),
+		),
+		mcp.WithBoolean("include_deleted",
+			mcp.Description("Use queryAll to include soft-deleted records in the recycle bin and archived activities (default: false)"),
+		),
+		mcp.WithBoolean("validate",
+			mcp.Description("Check objects, fields and relationship paths against the org's metadata first and return any problems without running the query. A query the local parser cannot read still runs, with the parse error as a warning (default: false)"),
+		),
+		withOrgArgument(),
 	)
 }
 
@@ -34,21 +44,44 @@ func QueryHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallTo
 		format = "json"
 	}
 
-	// Load configuration
-	config := pkg.LoadConfig()
+	maxRecords := request.GetInt("max_records", pkg.DefaultMaxRecords)
+	if maxRecords <= 0 {
+		maxRecords = pkg.DefaultMaxRecords
+	}
 
-	// Get authenticated Salesforce client (with connection reuse)
-	clientManager := pkg.GetClientManager(config)
-	sfClient, err := clientManager.GetClient()
+	// Get authenticated Salesforce client for the requested org
+	sfClient, err := getSalesforceClient(request)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Authentication failed: %v", err)), nil
 	}
 
+	// Catch misspelled objects and fields before spending a query round trip. The local
+	// parser may not know every SOQL construct, so only schema problems stop the query.
+	var warnings []string
+	if request.GetBool("validate", false) {
+		validation, err := sfClient.ValidateSOQL(ctx, soql)
+		switch {
+		case err != nil:
+			pkg.Logger().WarnContext(ctx, "query validation failed, running the query anyway", "error", err)
+		case !validation.Parsed:
+			problem := validation.Problems[0]
+			warnings = append(warnings, fmt.Sprintf("query was not validated: line %d:%d: %s", problem.Line, problem.Column, problem.Message))
+		case !validation.Valid:
+			return mcp.NewToolResultError("Query validation failed:\n" + pkg.FormatSOQLProblems(validation)), nil
+		}
+	}
+
 	// Execute SOQL query
-	result, err := sfClient.Query(soql)
+	var result *pkg.SalesforceQueryResponse
+	if request.GetBool("include_deleted", false) {
+		result, err = sfClient.QueryAll(ctx, soql, maxRecords)
+	} else {
+		result, err = sfClient.Query(ctx, soql, maxRecords)
+	}
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Query execution failed: %v", err)), nil
+		return queryErrorResult("Query execution failed", err), nil
 	}
+	result.Warnings = append(result.Warnings, warnings...)
 
 	// Format and return results
 	var output string
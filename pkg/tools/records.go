@@ -0,0 +1,207 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/zhongxiao37/soql-mcp/pkg"
+)
+
+// withDryRunArgument adds the dry_run argument shared by the record write tools
+func withDryRunArgument() mcp.ToolOption {
+	return mcp.WithBoolean("dry_run",
+		mcp.Description("Validate the change against the object's describe metadata without writing anything (default: false)"),
+	)
+}
+
+// withFieldsArgument adds the fields argument shared by the create, update and upsert tools
+func withFieldsArgument() mcp.ToolOption {
+	return mcp.WithObject("fields",
+		mcp.Required(),
+		mcp.Description("Field values keyed by field API name (e.g., {\"Name\": \"Acme\", \"Industry\": \"Energy\"})"),
+	)
+}
+
+// CreateCreateRecordTool creates a tool that inserts a record
+func CreateCreateRecordTool() mcp.Tool {
+	return mcp.NewTool("create_record",
+		mcp.WithDescription("Create a Salesforce record"),
+		mcp.WithString("object",
+			mcp.Required(),
+			mcp.Description("The Salesforce object name (e.g., Account)"),
+		),
+		withFieldsArgument(),
+		withDryRunArgument(),
+		withOrgArgument(),
+	)
+}
+
+// CreateUpdateRecordTool creates a tool that updates a record by ID
+func CreateUpdateRecordTool() mcp.Tool {
+	return mcp.NewTool("update_record",
+		mcp.WithDescription("Update fields on an existing Salesforce record"),
+		mcp.WithString("object",
+			mcp.Required(),
+			mcp.Description("The Salesforce object name (e.g., Account)"),
+		),
+		mcp.WithString("id",
+			mcp.Required(),
+			mcp.Description("The 15 or 18 character record ID"),
+		),
+		withFieldsArgument(),
+		withDryRunArgument(),
+		withOrgArgument(),
+	)
+}
+
+// CreateUpsertRecordTool creates a tool that upserts a record by external ID
+func CreateUpsertRecordTool() mcp.Tool {
+	return mcp.NewTool("upsert_record",
+		mcp.WithDescription("Create or update a Salesforce record matched by an external ID field"),
+		mcp.WithString("object",
+			mcp.Required(),
+			mcp.Description("The Salesforce object name (e.g., Account)"),
+		),
+		mcp.WithString("external_id_field",
+			mcp.Required(),
+			mcp.Description("The external ID field to match on (e.g., External_Id__c)"),
+		),
+		mcp.WithString("external_id",
+			mcp.Required(),
+			mcp.Description("The external ID value of the record"),
+		),
+		withFieldsArgument(),
+		withDryRunArgument(),
+		withOrgArgument(),
+	)
+}
+
+// CreateDeleteRecordTool creates a tool that deletes a record by ID
+func CreateDeleteRecordTool() mcp.Tool {
+	return mcp.NewTool("delete_record",
+		mcp.WithDescription("Delete a Salesforce record, moving it to the recycle bin"),
+		mcp.WithString("object",
+			mcp.Required(),
+			mcp.Description("The Salesforce object name (e.g., Account)"),
+		),
+		mcp.WithString("id",
+			mcp.Required(),
+			mcp.Description("The 15 or 18 character record ID"),
+		),
+		withDryRunArgument(),
+		withOrgArgument(),
+	)
+}
+
+// CreateRecordHandler handles create_record requests
+func CreateRecordHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return recordWriteHandler(ctx, request, pkg.RecordCreate)
+}
+
+// UpdateRecordHandler handles update_record requests
+func UpdateRecordHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return recordWriteHandler(ctx, request, pkg.RecordUpdate)
+}
+
+// UpsertRecordHandler handles upsert_record requests
+func UpsertRecordHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return recordWriteHandler(ctx, request, pkg.RecordUpsert)
+}
+
+// DeleteRecordHandler handles delete_record requests
+func DeleteRecordHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return recordWriteHandler(ctx, request, pkg.RecordDelete)
+}
+
+// recordWriteHandler validates and, unless dry_run is set, performs one record write operation
+func recordWriteHandler(ctx context.Context, request mcp.CallToolRequest, operation string) (*mcp.CallToolResult, error) {
+	objectName, err := request.RequireString("object")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Object parameter is required: %v", err)), nil
+	}
+
+	var id, externalIDField, externalID string
+	switch operation {
+	case pkg.RecordUpdate, pkg.RecordDelete:
+		if id, err = request.RequireString("id"); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("ID parameter is required: %v", err)), nil
+		}
+	case pkg.RecordUpsert:
+		if externalIDField, err = request.RequireString("external_id_field"); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("External ID field parameter is required: %v", err)), nil
+		}
+		if externalID, err = request.RequireString("external_id"); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("External ID parameter is required: %v", err)), nil
+		}
+	}
+
+	var fields map[string]interface{}
+	if operation != pkg.RecordDelete {
+		if fields, err = recordFields(request); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Fields parameter is invalid: %v", err)), nil
+		}
+	}
+
+	// Get authenticated Salesforce client for the requested org
+	sfClient, err := getSalesforceClient(request)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Authentication failed: %v", err)), nil
+	}
+
+	// Validate against describe metadata without writing
+	if request.GetBool("dry_run", false) {
+		describe, err := sfClient.Describe(ctx, objectName, false)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Describe operation failed: %v", err)), nil
+		}
+
+		problems := pkg.ValidateRecordWrite(describe, operation, fields, id, externalIDField)
+		if len(problems) > 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("Dry run of %s on %s found %d problem(s):\n- %s",
+				operation, describe.Name, len(problems), strings.Join(problems, "\n- "))), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Dry run of %s on %s passed validation. No changes were made.", operation, describe.Name)), nil
+	}
+
+	// Perform the write
+	var result *pkg.SalesforceSaveResult
+	switch operation {
+	case pkg.RecordCreate:
+		result, err = sfClient.CreateRecord(ctx, objectName, fields)
+	case pkg.RecordUpdate:
+		err = sfClient.UpdateRecord(ctx, objectName, id, fields)
+		result = &pkg.SalesforceSaveResult{ID: id, Success: err == nil}
+	case pkg.RecordUpsert:
+		result, err = sfClient.UpsertRecord(ctx, objectName, externalIDField, externalID, fields)
+	case pkg.RecordDelete:
+		err = sfClient.DeleteRecord(ctx, objectName, id)
+		result = &pkg.SalesforceSaveResult{ID: id, Success: err == nil}
+	}
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Record %s failed: %v", operation, err)), nil
+	}
+
+	jsonBytes, _ := json.MarshalIndent(result, "", "  ")
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// recordFields reads the fields argument, accepting a JSON object or a JSON encoded string
+func recordFields(request mcp.CallToolRequest) (map[string]interface{}, error) {
+	switch value := request.GetArguments()["fields"].(type) {
+	case map[string]interface{}:
+		return value, nil
+	case string:
+		var fields map[string]interface{}
+		if err := json.Unmarshal([]byte(value), &fields); err != nil {
+			return nil, fmt.Errorf("fields must be a JSON object: %v", err)
+		}
+		return fields, nil
+	case nil:
+		return nil, fmt.Errorf("fields is required")
+	default:
+		return nil, fmt.Errorf("fields must be a JSON object")
+	}
+}
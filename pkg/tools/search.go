@@ -0,0 +1,60 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/zhongxiao37/soql-mcp/pkg"
+)
+
+// CreateSearchTool creates a new SOSL search tool
+func CreateSearchTool() mcp.Tool {
+	return mcp.NewTool("search",
+		mcp.WithDescription("Execute SOSL searches against Salesforce to find records across objects"),
+		mcp.WithString("sosl",
+			mcp.Required(),
+			mcp.Description("The SOSL search to execute (e.g., FIND {555-0100} IN PHONE FIELDS RETURNING Contact(Id, Name), Lead(Id, Name))"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format: 'json' or 'table' (default: json)"),
+		),
+		withOrgArgument(),
+	)
+}
+
+// SearchHandler handles SOSL search requests
+func SearchHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	sosl, err := request.RequireString("sosl")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Search parameter is required: %v", err)), nil
+	}
+
+	format := request.GetString("format", "json")
+	if format != "json" && format != "table" {
+		format = "json"
+	}
+
+	// Get authenticated Salesforce client for the requested org
+	sfClient, err := getSalesforceClient(request)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Authentication failed: %v", err)), nil
+	}
+
+	// Execute SOSL search
+	result, err := sfClient.Search(ctx, sosl)
+	if err != nil {
+		return queryErrorResult("Search execution failed", err), nil
+	}
+
+	// Format and return results
+	if format == "table" {
+		return mcp.NewToolResultText(pkg.FormatSearchAsTable(result)), nil
+	}
+
+	toolResult := mcp.NewToolResultText(pkg.FormatSearchAsJSON(result))
+	for _, note := range result.PolicyNotes {
+		toolResult.Content = append(toolResult.Content, mcp.NewTextContent("Query policy: "+note))
+	}
+	return toolResult, nil
+}
@@ -0,0 +1,59 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/zhongxiao37/soql-mcp/pkg"
+)
+
+// CreateValidateSOQLTool creates a new SOQL validation tool
+func CreateValidateSOQLTool() mcp.Tool {
+	return mcp.NewTool("validate_soql",
+		mcp.WithDescription("Check a SOQL query's syntax, objects, fields and relationship paths against the org's metadata without running it, with 'did you mean' suggestions for misspelled names"),
+		mcp.WithString("soql",
+			mcp.Required(),
+			mcp.Description("The SOQL query to validate (e.g., SELECT Id, Name FROM Account LIMIT 10)"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format: 'json' or 'table' (default: table)"),
+		),
+		withOrgArgument(),
+	)
+}
+
+// ValidateSOQLHandler handles SOQL validation requests
+func ValidateSOQLHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	soql, err := request.RequireString("soql")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Query parameter is required: %v", err)), nil
+	}
+
+	format := request.GetString("format", "table")
+	if format != "json" && format != "table" {
+		format = "table"
+	}
+
+	// Get authenticated Salesforce client for the requested org
+	sfClient, err := getSalesforceClient(request)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Authentication failed: %v", err)), nil
+	}
+
+	// Validate the query against the org's metadata
+	result, err := sfClient.ValidateSOQL(ctx, soql)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Query validation failed: %v", err)), nil
+	}
+
+	// Format and return results
+	var output string
+	if format == "json" {
+		output = pkg.FormatSOQLValidationAsJSON(result)
+	} else {
+		output = pkg.FormatSOQLValidationAsTable(result)
+	}
+
+	return mcp.NewToolResultText(output), nil
+}
@@ -0,0 +1,494 @@
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/zhongxiao37/soql-mcp/pkg/soql"
+)
+
+// maxSOQLSuggestions is the number of "did you mean" suggestions per problem
+const maxSOQLSuggestions = 3
+
+// SOQLProblem is a problem found in a query, located at the offending token
+type SOQLProblem struct {
+	Line        int      `json:"line"`
+	Column      int      `json:"column"`
+	Token       string   `json:"token,omitempty"`
+	Message     string   `json:"message"`
+	Suggestions []string `json:"suggestions,omitempty"`
+}
+
+// SalesforceSOQLValidation is the result of validating a query against the org's metadata
+type SalesforceSOQLValidation struct {
+	Query string `json:"query"`
+	Valid bool   `json:"valid"`
+	// Parsed is false for a syntax error; Problems then holds that error and the
+	// objects and fields were not checked
+	Parsed    bool          `json:"parsed"`
+	Canonical string        `json:"canonical,omitempty"`
+	Problems  []SOQLProblem `json:"problems,omitempty"`
+}
+
+// ValidateSOQL parses a query and checks every referenced object, field and relationship
+// path against the (cached) describe metadata, without running the query. Problems with the
+// query are reported in the result; an error means the metadata could not be loaded.
+func (sf *SalesforceClient) ValidateSOQL(ctx context.Context, query string) (*SalesforceSOQLValidation, error) {
+	result := &SalesforceSOQLValidation{Query: query}
+
+	parsed, err := soql.Parse(query)
+	if err != nil {
+		var parseErr *soql.ParseError
+		if !errors.As(err, &parseErr) {
+			return nil, err
+		}
+		result.Problems = []SOQLProblem{{
+			Line:    parseErr.Pos.Line,
+			Column:  parseErr.Pos.Column,
+			Message: parseErr.Message,
+		}}
+		return result, nil
+	}
+	result.Parsed = true
+	result.Canonical = parsed.String()
+
+	validator := &soqlValidator{describeMemo: newDescribeMemo(sf)}
+	if err := validator.query(ctx, parsed, nil); err != nil {
+		return nil, err
+	}
+	result.Problems = validator.problems
+	result.Valid = len(result.Problems) == 0
+	return result, nil
+}
+
+// soqlValidator checks a parsed query against describe metadata
+type soqlValidator struct {
+	*describeMemo
+	global   *SalesforceDescribeGlobalResponse
+	problems []SOQLProblem
+}
+
+// describeMemo describes each object once while a query is checked
+type describeMemo struct {
+	sf        *SalesforceClient
+	describes map[string]*SalesforceDescribeResponse
+}
+
+func newDescribeMemo(sf *SalesforceClient) *describeMemo {
+	return &describeMemo{sf: sf, describes: map[string]*SalesforceDescribeResponse{}}
+}
+
+// soqlCandidate is a name that can be suggested for a misspelled token, with its label
+type soqlCandidate struct {
+	Name  string
+	Label string
+}
+
+func (v *soqlValidator) problem(pos soql.Position, token string, suggestions []string, format string, args ...any) {
+	v.problems = append(v.problems, SOQLProblem{
+		Line:        pos.Line,
+		Column:      pos.Column,
+		Token:       token,
+		Message:     fmt.Sprintf(format, args...),
+		Suggestions: suggestions,
+	})
+}
+
+// describe returns the describe result of an object
+func (m *describeMemo) describe(ctx context.Context, objectType string) (*SalesforceDescribeResponse, error) {
+	key := strings.ToLower(objectType)
+	if describe, ok := m.describes[key]; ok {
+		return describe, nil
+	}
+	describe, err := m.sf.Describe(ctx, objectType, false)
+	if err != nil {
+		return nil, err
+	}
+	m.describes[key] = describe
+	return describe, nil
+}
+
+// object describes the object a top level query or semi-join reads from. An object missing
+// from describeGlobal is reported as a problem and nil is returned.
+func (v *soqlValidator) object(ctx context.Context, ref *soql.ObjectRef) (*SalesforceDescribeResponse, error) {
+	describe, err := v.describe(ctx, ref.Name)
+	if err == nil {
+		if !describe.Queryable {
+			v.problem(ref.Pos(), ref.Name, nil, "object %s is not queryable", describe.Name)
+		}
+		return describe, nil
+	}
+
+	// Describe does not tell a missing object apart from other failures, so look it up
+	if v.global == nil {
+		global, globalErr := v.sf.DescribeGlobal(ctx)
+		if globalErr != nil {
+			return nil, err
+		}
+		v.global = global
+	}
+	var candidates []soqlCandidate
+	for _, sobject := range v.global.SObjects {
+		if strings.EqualFold(sobject.Name, ref.Name) {
+			return nil, err
+		}
+		candidates = append(candidates, soqlCandidate{Name: sobject.Name, Label: sobject.Label})
+	}
+	v.problem(ref.Pos(), ref.Name, suggestSOQLNames(ref.Name, candidates), "unknown object %s", ref.Name)
+	return nil, nil
+}
+
+// query validates a query. Subqueries in the select list read from a child relationship
+// of parent; top level queries and semi-joins have no parent.
+func (v *soqlValidator) query(ctx context.Context, q *soql.Query, parent *SalesforceDescribeResponse) error {
+	var object *SalesforceDescribeResponse
+	var err error
+	if parent == nil {
+		object, err = v.object(ctx, q.From)
+	} else {
+		object, err = v.childRelationship(ctx, parent, q.From)
+	}
+	if err != nil || object == nil {
+		return err
+	}
+
+	alias := q.From.Alias
+	for _, item := range q.Select {
+		switch item := item.(type) {
+		case *soql.SelectExpr:
+			err = v.expr(ctx, object, alias, item.Expr)
+		case *soql.Query:
+			err = v.query(ctx, item, object)
+		case *soql.TypeOf:
+			err = v.typeOf(ctx, object, item)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, condition := range []soql.Condition{q.Where, q.Having} {
+		if condition == nil {
+			continue
+		}
+		if err := v.condition(ctx, object, alias, condition); err != nil {
+			return err
+		}
+	}
+
+	var exprs []soql.Expr
+	if q.GroupBy != nil {
+		exprs = append(exprs, q.GroupBy.Exprs...)
+	}
+	for _, item := range q.OrderBy {
+		exprs = append(exprs, item.Expr)
+	}
+	for _, expr := range exprs {
+		if err := v.expr(ctx, object, alias, expr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// childRelationship describes the child object of a parent-to-child subquery
+func (v *soqlValidator) childRelationship(ctx context.Context, parent *SalesforceDescribeResponse, ref *soql.ObjectRef) (*SalesforceDescribeResponse, error) {
+	var candidates []soqlCandidate
+	for _, relationship := range parent.ChildRelationships {
+		if relationship.RelationshipName == "" {
+			continue
+		}
+		if strings.EqualFold(relationship.RelationshipName, ref.Name) {
+			return v.describe(ctx, relationship.ChildSObject)
+		}
+		candidates = append(candidates, soqlCandidate{Name: relationship.RelationshipName, Label: relationship.ChildSObject})
+	}
+	v.problem(ref.Pos(), ref.Name, suggestSOQLNames(ref.Name, candidates),
+		"unknown child relationship %s on %s", ref.Name, parent.Name)
+	return nil, nil
+}
+
+// typeOf validates a TYPEOF expression on a polymorphic relationship
+func (v *soqlValidator) typeOf(ctx context.Context, object *SalesforceDescribeResponse, typeOf *soql.TypeOf) error {
+	field := findRelationshipField(object, typeOf.Relationship)
+	if field == nil {
+		v.problem(typeOf.RelationshipPos, typeOf.Relationship, suggestSOQLNames(typeOf.Relationship, relationshipCandidates(object)),
+			"unknown relationship %s on %s", typeOf.Relationship, object.Name)
+		return nil
+	}
+
+	for _, when := range typeOf.Whens {
+		if !containsFold(field.ReferenceTo, when.Type) {
+			var candidates []soqlCandidate
+			for _, referenceTo := range field.ReferenceTo {
+				candidates = append(candidates, soqlCandidate{Name: referenceTo})
+			}
+			v.problem(when.TypePos, when.Type, suggestSOQLNames(when.Type, candidates),
+				"%s.%s cannot reference %s", object.Name, field.RelationshipName, when.Type)
+			continue
+		}
+		target, err := v.describe(ctx, when.Type)
+		if err != nil {
+			return err
+		}
+		for _, whenField := range when.Fields {
+			if err := v.field(ctx, target, "", whenField); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// condition validates the fields of a WHERE or HAVING condition and its semi-joins
+func (v *soqlValidator) condition(ctx context.Context, object *SalesforceDescribeResponse, alias string, condition soql.Condition) error {
+	switch condition := condition.(type) {
+	case *soql.Comparison:
+		if err := v.expr(ctx, object, alias, condition.Left); err != nil {
+			return err
+		}
+		if subquery, ok := condition.Right.(*soql.Query); ok {
+			return v.query(ctx, subquery, nil)
+		}
+	case *soql.LogicalCondition:
+		for _, nested := range condition.Conditions {
+			if err := v.condition(ctx, object, alias, nested); err != nil {
+				return err
+			}
+		}
+	case *soql.NotCondition:
+		return v.condition(ctx, object, alias, condition.Condition)
+	}
+	return nil
+}
+
+// expr validates a field reference or the field arguments of a function call
+func (v *soqlValidator) expr(ctx context.Context, object *SalesforceDescribeResponse, alias string, expr soql.Expr) error {
+	switch expr := expr.(type) {
+	case *soql.FieldRef:
+		return v.field(ctx, object, alias, expr)
+	case *soql.FunctionCall:
+		// FIELDS takes ALL, STANDARD or CUSTOM rather than a field
+		if strings.EqualFold(expr.Name, "FIELDS") {
+			return nil
+		}
+		for _, arg := range expr.Args {
+			if argExpr, ok := arg.(soql.Expr); ok {
+				if err := v.expr(ctx, object, alias, argExpr); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// field follows a relationship path such as Account.Owner.Name from object and checks
+// that the last element is a field. Paths through polymorphic relationships are only
+// checked up to the polymorphic relationship.
+func (v *soqlValidator) field(ctx context.Context, object *SalesforceDescribeResponse, alias string, ref *soql.FieldRef) error {
+	path, positions := ref.Path, ref.PathPos
+	if alias != "" && len(path) > 1 && strings.EqualFold(path[0], alias) {
+		path, positions = path[1:], positions[1:]
+	}
+
+	current := object
+	for i, name := range path {
+		if i == len(path)-1 {
+			if findField(current, name) == nil {
+				v.problem(positions[i], name, suggestSOQLNames(name, fieldCandidates(current)),
+					"unknown field %s on %s", name, current.Name)
+			}
+			return nil
+		}
+
+		relationship := findRelationshipField(current, name)
+		if relationship == nil {
+			message := "unknown relationship %s on %s"
+			if field := findField(current, name); field != nil && len(field.ReferenceTo) > 0 {
+				message = "%s is a field on %s; use its relationship name to traverse it"
+			}
+			v.problem(positions[i], name, suggestSOQLNames(name, relationshipCandidates(current)),
+				message, name, current.Name)
+			return nil
+		}
+		if len(relationship.ReferenceTo) != 1 {
+			return nil
+		}
+
+		next, err := v.describe(ctx, relationship.ReferenceTo[0])
+		if err != nil {
+			return err
+		}
+		current = next
+	}
+	return nil
+}
+
+// findField returns the field with the given API name
+func findField(object *SalesforceDescribeResponse, name string) *SalesforceDescribeField {
+	for i := range object.Fields {
+		if strings.EqualFold(object.Fields[i].Name, name) {
+			return &object.Fields[i]
+		}
+	}
+	return nil
+}
+
+// findRelationshipField returns the lookup field with the given relationship name
+func findRelationshipField(object *SalesforceDescribeResponse, relationshipName string) *SalesforceDescribeField {
+	for i := range object.Fields {
+		if object.Fields[i].RelationshipName != "" && strings.EqualFold(object.Fields[i].RelationshipName, relationshipName) {
+			return &object.Fields[i]
+		}
+	}
+	return nil
+}
+
+func fieldCandidates(object *SalesforceDescribeResponse) []soqlCandidate {
+	candidates := make([]soqlCandidate, len(object.Fields))
+	for i, field := range object.Fields {
+		candidates[i] = soqlCandidate{Name: field.Name, Label: field.Label}
+	}
+	return candidates
+}
+
+func relationshipCandidates(object *SalesforceDescribeResponse) []soqlCandidate {
+	var candidates []soqlCandidate
+	for _, field := range object.Fields {
+		if field.RelationshipName != "" {
+			candidates = append(candidates, soqlCandidate{Name: field.RelationshipName, Label: field.Label})
+		}
+	}
+	return candidates
+}
+
+func containsFold(values []string, value string) bool {
+	for _, candidate := range values {
+		if strings.EqualFold(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// suggestSOQLNames returns the candidates closest to a misspelled token, comparing the
+// token with each API name (with and without its __c or __r suffix) and label
+func suggestSOQLNames(token string, candidates []soqlCandidate) []string {
+	token = strings.ToLower(token)
+	threshold := max(2, len(token)/3)
+
+	type scored struct {
+		name     string
+		distance int
+	}
+	var matches []scored
+	seen := map[string]bool{}
+	for _, candidate := range candidates {
+		name := strings.ToLower(candidate.Name)
+		if seen[name] {
+			continue
+		}
+		distance := editDistance(token, name)
+		if trimmed := strings.TrimSuffix(strings.TrimSuffix(name, "__c"), "__r"); trimmed != name {
+			distance = min(distance, editDistance(token, trimmed))
+		}
+		if candidate.Label != "" {
+			label := strings.ToLower(strings.ReplaceAll(candidate.Label, " ", ""))
+			distance = min(distance, editDistance(token, label))
+		}
+		if distance <= threshold {
+			seen[name] = true
+			matches = append(matches, scored{name: candidate.Name, distance: distance})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].distance != matches[j].distance {
+			return matches[i].distance < matches[j].distance
+		}
+		return matches[i].name < matches[j].name
+	})
+	var suggestions []string
+	for i := 0; i < len(matches) && i < maxSOQLSuggestions; i++ {
+		suggestions = append(suggestions, matches[i].name)
+	}
+	return suggestions
+}
+
+// editDistance returns the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	previous := make([]int, len(br)+1)
+	current := make([]int, len(br)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ar); i++ {
+		current[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(br)]
+}
+
+// FormatSOQLProblems formats validation problems, each followed by the query line with a
+// caret under the offending token and any suggestions
+func FormatSOQLProblems(result *SalesforceSOQLValidation) string {
+	var buffer bytes.Buffer
+	lines := strings.Split(result.Query, "\n")
+	for _, problem := range result.Problems {
+		buffer.WriteString(fmt.Sprintf("line %d:%d: %s\n", problem.Line, problem.Column, problem.Message))
+		if problem.Line >= 1 && problem.Line <= len(lines) {
+			line := []rune(strings.TrimRight(lines[problem.Line-1], "\r"))
+			indent := make([]rune, 0, problem.Column)
+			for i := 0; i < problem.Column-1 && i < len(line); i++ {
+				// Keep tabs so the caret lines up
+				if line[i] == '\t' {
+					indent = append(indent, '\t')
+				} else {
+					indent = append(indent, ' ')
+				}
+			}
+			buffer.WriteString(fmt.Sprintf("  %s\n  %s^\n", string(line), string(indent)))
+		}
+		if len(problem.Suggestions) > 0 {
+			buffer.WriteString(fmt.Sprintf("  Did you mean: %s?\n", strings.Join(problem.Suggestions, ", ")))
+		}
+	}
+	return buffer.String()
+}
+
+// FormatSOQLValidationAsTable formats a validation result as readable text
+func FormatSOQLValidationAsTable(result *SalesforceSOQLValidation) string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("Valid: %t\n", result.Valid))
+	if result.Canonical != "" {
+		buffer.WriteString(fmt.Sprintf("Canonical: %s\n", result.Canonical))
+	}
+	if len(result.Problems) == 0 {
+		return buffer.String()
+	}
+
+	buffer.WriteString(fmt.Sprintf("\nProblems (%d):\n", len(result.Problems)))
+	buffer.WriteString(strings.Repeat("-", 50) + "\n")
+	buffer.WriteString(FormatSOQLProblems(result))
+	return buffer.String()
+}
+
+// FormatSOQLValidationAsJSON formats a validation result as JSON
+func FormatSOQLValidationAsJSON(result *SalesforceSOQLValidation) string {
+	jsonBytes, _ := json.MarshalIndent(result, "", "  ")
+	return string(jsonBytes)
+}
@@ -0,0 +1,241 @@
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testDescribes is the metadata served by newDescribeTestClient
+var testDescribes = map[string]*SalesforceDescribeResponse{
+	"Account": {
+		Name: "Account", Label: "Account", Queryable: true,
+		Fields: []SalesforceDescribeField{
+			{Name: "Id", Label: "Account ID"},
+			{Name: "Name", Label: "Account Name"},
+			{Name: "Industry", Label: "Industry"},
+			{Name: "AnnualRevenue", Label: "Annual Revenue"},
+			{Name: "OwnerId", Label: "Owner ID", ReferenceTo: []string{"User"}, RelationshipName: "Owner"},
+		},
+		ChildRelationships: []SalesforceChildRelationship{
+			{ChildSObject: "Contact", Field: "AccountId", RelationshipName: "Contacts"},
+		},
+	},
+	"Contact": {
+		Name: "Contact", Label: "Contact", Queryable: true,
+		Fields: []SalesforceDescribeField{
+			{Name: "Id", Label: "Contact ID"},
+			{Name: "Email", Label: "Email"},
+			{Name: "SSN__c", Label: "Social Security Number"},
+			{Name: "AccountId", Label: "Account ID", ReferenceTo: []string{"Account"}, RelationshipName: "Account"},
+		},
+	},
+	"User": {
+		Name: "User", Label: "User", Queryable: true,
+		Fields: []SalesforceDescribeField{
+			{Name: "Id", Label: "User ID"},
+			{Name: "Name", Label: "Full Name"},
+			{Name: "Email", Label: "Email"},
+		},
+	},
+	"Order": {
+		Name: "Order", Label: "Order", Queryable: true,
+		Fields: []SalesforceDescribeField{
+			{Name: "Id", Label: "Order ID"},
+			{Name: "OrderNumber", Label: "Order Number"},
+		},
+	},
+}
+
+// newDescribeTestClient returns a client for a test server that serves describe and
+// describeGlobal from testDescribes
+func newDescribeTestClient(t *testing.T) *SalesforceClient {
+	t.Helper()
+	const prefix = "/services/data/v62.0/sobjects"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == prefix {
+			global := SalesforceDescribeGlobalResponse{}
+			for name, describe := range testDescribes {
+				global.SObjects = append(global.SObjects, SalesforceSObjectSummary{Name: name, Label: describe.Label, Queryable: true})
+			}
+			json.NewEncoder(w).Encode(global)
+			return
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, prefix+"/"), "/describe")
+		for objectName, describe := range testDescribes {
+			if strings.EqualFold(objectName, name) {
+				json.NewEncoder(w).Encode(describe)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`[{"message":"The requested resource does not exist","errorCode":"NOT_FOUND"}]`))
+	}))
+	t.Cleanup(server.Close)
+
+	sf := NewSalesforceClient(&OrgConfig{Name: "test"})
+	sf.auth = &SalesforceAuth{AccessToken: "token", InstanceURL: server.URL}
+	sf.version = "v62.0"
+	return sf
+}
+
+func TestValidateSOQL(t *testing.T) {
+	sf := newDescribeTestClient(t)
+
+	tests := []struct {
+		name        string
+		query       string
+		valid       bool
+		parsed      bool
+		messages    []string
+		suggestions [][]string
+	}{
+		{
+			name:   "valid query",
+			query:  "SELECT Id, Name, Owner.Email, (SELECT Email FROM Contacts) FROM Account WHERE Industry = 'Tech' ORDER BY Name",
+			valid:  true,
+			parsed: true,
+		},
+		{
+			name:   "keyword object name",
+			query:  "SELECT Id, OrderNumber FROM Order",
+			valid:  true,
+			parsed: true,
+		},
+		{
+			name:        "misspelled field",
+			query:       "SELECT Id, Nmae FROM Account",
+			parsed:      true,
+			messages:    []string{"unknown field Nmae on Account"},
+			suggestions: [][]string{{"Name"}},
+		},
+		{
+			name:        "misspelled object",
+			query:       "SELECT Id FROM Acount",
+			parsed:      true,
+			messages:    []string{"unknown object Acount"},
+			suggestions: [][]string{{"Account"}},
+		},
+		{
+			name:        "field through relationship",
+			query:       "SELECT Owner.Emial FROM Account",
+			parsed:      true,
+			messages:    []string{"unknown field Emial on User"},
+			suggestions: [][]string{{"Email"}},
+		},
+		{
+			name:        "lookup field used as relationship",
+			query:       "SELECT OwnerId.Name FROM Account",
+			parsed:      true,
+			messages:    []string{"OwnerId is a field on Account; use its relationship name to traverse it"},
+			suggestions: [][]string{{"Owner"}},
+		},
+		{
+			name:        "unknown child relationship",
+			query:       "SELECT Id, (SELECT Id FROM Contact) FROM Account",
+			parsed:      true,
+			messages:    []string{"unknown child relationship Contact on Account"},
+			suggestions: [][]string{{"Contacts"}},
+		},
+		{
+			name:        "syntax error",
+			query:       "SELECT Id FROM Account WHERE",
+			messages:    []string{"expected field name, found end of query"},
+			suggestions: [][]string{nil},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := sf.ValidateSOQL(context.Background(), test.query)
+			if err != nil {
+				t.Fatalf("ValidateSOQL(%q) failed: %v", test.query, err)
+			}
+			if result.Valid != test.valid || result.Parsed != test.parsed {
+				t.Errorf("Valid, Parsed = %t, %t, want %t, %t", result.Valid, result.Parsed, test.valid, test.parsed)
+			}
+			var messages []string
+			var suggestions [][]string
+			for _, problem := range result.Problems {
+				messages = append(messages, problem.Message)
+				suggestions = append(suggestions, problem.Suggestions)
+			}
+			if !reflect.DeepEqual(messages, test.messages) {
+				t.Errorf("messages = %q, want %q", messages, test.messages)
+			}
+			if !reflect.DeepEqual(suggestions, test.suggestions) {
+				t.Errorf("suggestions = %q, want %q", suggestions, test.suggestions)
+			}
+		})
+	}
+}
+
+func TestSuggestSOQLNames(t *testing.T) {
+	candidates := []soqlCandidate{
+		{Name: "Name", Label: "Account Name"},
+		{Name: "AnnualRevenue", Label: "Annual Revenue"},
+		{Name: "Industry", Label: "Industry"},
+		{Name: "Region__c", Label: "Sales Region"},
+	}
+
+	tests := []struct {
+		token string
+		want  []string
+	}{
+		{token: "Nmae", want: []string{"Name"}},
+		{token: "industy", want: []string{"Industry"}},
+		{token: "Region", want: []string{"Region__c"}},
+		{token: "SalesRegion", want: []string{"Region__c"}},
+		{token: "Revenue", want: nil},
+		{token: "Xyzzy", want: nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.token, func(t *testing.T) {
+			if got := suggestSOQLNames(test.token, candidates); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("suggestSOQLNames(%q) = %q, want %q", test.token, got, test.want)
+			}
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "", b: "", want: 0},
+		{a: "name", b: "", want: 4},
+		{a: "name", b: "name", want: 0},
+		{a: "name", b: "nmae", want: 2},
+		{a: "kitten", b: "sitting", want: 3},
+		{a: "café", b: "cafe", want: 1},
+	}
+
+	for _, test := range tests {
+		if got := editDistance(test.a, test.b); got != test.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestFormatSOQLProblems(t *testing.T) {
+	result := &SalesforceSOQLValidation{
+		Query: "SELECT Id,\n\tNmae FROM Account",
+		Problems: []SOQLProblem{
+			{Line: 2, Column: 2, Token: "Nmae", Message: "unknown field Nmae on Account", Suggestions: []string{"Name"}},
+		},
+	}
+	want := "line 2:2: unknown field Nmae on Account\n" +
+		"  \tNmae FROM Account\n" +
+		"  \t^\n" +
+		"  Did you mean: Name?\n"
+	if got := FormatSOQLProblems(result); got != want {
+		t.Errorf("FormatSOQLProblems() = %q, want %q", got, want)
+	}
+}